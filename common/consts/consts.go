@@ -24,6 +24,7 @@ const (
 	HttpMethodDelete  = "DELETE"
 	HttpMethodOptions = "OPTIONS"
 	HttpMethodHead    = "HEAD"
+	HttpMethodAny     = "ANY"
 )
 
 const (
@@ -44,11 +45,46 @@ const (
 	ApiRawBody       = "api.raw_body"
 	ApiBaseDomain    = "api.base_domain"
 	ApiBaseURL       = "api.baseurl"
+	ApiDeprecated    = "api.deprecated"
+	ApiResponseCode  = "api.response_code"
+	ApiContentType   = "api.content_type"
+	ApiVd            = "api.vd"
+	ApiFormat        = "api.format"
+	ApiNone          = "api.none"
+	Deprecated       = "deprecated"
 	OpenapiOperation = "openapi.operation"
 	OpenapiProperty  = "openapi.property"
 	OpenapiSchema    = "openapi.schema"
 	OpenapiParameter = "openapi.parameter"
 	OpenapiDocument  = "openapi.document"
+	OpenapiSecurity  = "openapi.security"
+
+	OpenapiContentTypes    = "openapi.content_types"
+	OpenapiResponseHeaders = "openapi.response_headers"
+	OpenapiExternalDocs    = "openapi.external_docs"
+	OpenapiTag             = "openapi.tag"
+	OpenapiWebhook         = "openapi.webhook"
+	OpenapiCallback        = "openapi.callback"
+	OpenapiExtension       = "openapi.extension"
+	OpenapiIgnore          = "openapi.ignore"
+	OpenapiSunset          = "openapi.sunset"
+	OpenapiEnumConst       = "openapi.enum_const"
+	OpenapiSchemaName      = "openapi.schema_name"
+	OpenapiExampleConst    = "openapi.example_const"
+	OpenapiReadOnly        = "openapi.read_only"
+	OpenapiWriteOnly       = "openapi.write_only"
+)
+
+const (
+	KitexStreamingMode = "streaming.mode"
+
+	StreamingModeUnary         = "unary"
+	StreamingModeClient        = "client"
+	StreamingModeServer        = "server"
+	StreamingModeBidirectional = "bidirectional"
+
+	ExtensionStreaming     = "x-streaming"
+	ExtensionStreamingBidi = "bidi"
 )
 
 const (
@@ -67,6 +103,8 @@ const (
 
 const (
 	OpenAPIVersion        = "3.0.3"
+	OpenAPIVersion31      = "3.1.0"
+	AsyncAPIVersion       = "2.6.0"
 	InfoURL               = "https://github.com/hertz-contrib/swagger-generate/"
 	URLDefaultPrefixHTTP  = "http://"
 	URLDefaultPrefixHTTPS = "https://"
@@ -74,15 +112,24 @@ const (
 	DefaultInfoDesc       = "API description"
 	DefaultInfoVersion    = "0.0.1"
 
+	DefaultOperationIDTemplate = "{{.Service}}_{{.Method}}"
+
 	DocumentOptionServiceType = "service"
 	DocumentOptionStructType  = "struct"
 
 	DefaultResponseDesc          = "Successful response"
 	DefaultExceptionDesc         = "Exception response"
+	SunsetHeaderDesc             = "Date after which the deprecated operation may stop working"
+	HeaderSunset                 = "Sunset"
+	ExtensionSunset              = "x-sunset"
+	ExtensionEnumDescriptions    = "x-enumDescriptions"
 	StatusOK                     = "200"
+	StatusNoContent              = "204"
 	StatusBadRequest             = "400"
+	StatusInternalServerError    = "500"
 	SchemaObjectType             = "object"
 	ComponentSchemaPrefix        = "#/components/schemas/"
+	ComponentParameterPrefix     = "#/components/parameters/"
 	ComponentSchemaSuffixBody    = "Body"
 	ComponentSchemaSuffixForm    = "Form"
 	ComponentSchemaSuffixRawBody = "RawBody"
@@ -91,25 +138,57 @@ const (
 	ContentTypeFormMultipart  = "multipart/form-data"
 	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
 	ContentTypeRawBody        = "text/plain"
+	ContentTypeOctetStream    = "application/octet-stream"
 
 	ParameterInQuery  = "query"
 	ParameterInHeader = "header"
 	ParameterInPath   = "path"
 	ParameterInCookie = "cookie"
 
-	DefaultOutputDir         = "swagger"
-	DefaultOutputYamlFile    = "openapi.yaml"
-	DefaultOutputSwaggerFile = "swagger.go"
+	DefaultOutputDir          = "swagger"
+	DefaultOutputYamlFile     = "openapi.yaml"
+	DefaultOutputSwaggerFile  = "swagger.go"
+	DefaultOutputSchemaDir    = "schemas"
+	DefaultOutputAsyncAPIFile = "asyncapi.yaml"
+
+	OutputModeSplit          = "split"
+	OutputModeSourceRelative = "source_relative"
+	OutputModeBoth           = "both"
+	OutputModeService        = "service"
+
+	HttpAnnotationHertz  = "hertz"
+	HttpAnnotationGoogle = "google"
+
+	SpecOpenAPI  = "openapi"
+	SpecAsyncAPI = "asyncapi"
+
+	EnumTypeString  = "string"
+	EnumTypeInteger = "integer"
 
 	DefaultServerURL = "http://127.0.0.1:8888"
 	DefaultKitexAddr = "127.0.0.1:8888"
+	DefaultHertzAddr = "127.0.0.1:8443"
+
+	DefaultProxyPrefix = "/api/"
+	DefaultRPCTimeout  = "5s"
+	MaxRetryTimes      = 5
+
+	RegistryEtcd              = "etcd"
+	RegistryConsul            = "consul"
+	DefaultEtcdRegistryAddr   = "127.0.0.1:2379"
+	DefaultConsulRegistryAddr = "127.0.0.1:8500"
 
 	ParameterNameTTHeader = "ttheader"
 	ParameterDescription  = "metainfo for request"
 
 	CommentPatternRegexp    = `//\s*(.*)|/\*([\s\S]*?)\*/`
+	MarkdownCommentPattern  = `//(.*)|/\*([\s\S]*?)\*/`
 	LinterRulePatternRegexp = `\(-- .* --\)`
 
+	VdComparePatternRegexp = `^\$\s*(>=|<=|>|<|==)\s*(-?\d+(?:\.\d+)?)$`
+	VdLenPatternRegexp     = `^len\(\$\)\s*(>=|<=|>|<|==)\s*(\d+)$`
+	VdRegexpPatternRegexp  = `^regexp\(\s*'(.*)'\s*\)$`
+
 	ProtobufValueName = "GoogleProtobufValue"
 	ProtobufAnyName   = "GoogleProtobufAny"
 )
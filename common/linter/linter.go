@@ -0,0 +1,182 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package linter checks OpenAPI documents for common issues, such as operations without responses
+// or duplicate operation IDs, on top of the validation against the OpenAPI 3 specification.
+package linter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hertz-contrib/swagger-generate/common/utils"
+)
+
+// Severity is the severity of a rule violation.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// severityRanks orders the severities from the least to the most severe.
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// AtLeast reports whether the severity is at least as severe as the other one.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// ParseSeverity returns the severity of the name, which is one of error, warning and info.
+func ParseSeverity(name string) (Severity, error) {
+	if _, ok := severityRanks[Severity(name)]; !ok {
+		return "", fmt.Errorf("unknown severity '%s', it must be one of error, warning and info", name)
+	}
+	return Severity(name), nil
+}
+
+const (
+	RuleInvalidDocument      = "invalid-document"
+	RuleMissingResponses     = "missing-responses"
+	RuleMissingOperationID   = "missing-operation-id"
+	RuleDuplicateOperationID = "duplicate-operation-id"
+	RuleMissingDescription   = "missing-description"
+)
+
+// Violation is a violation of a rule at a location of the document, e.g. `paths./hello.get`.
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Location string   `json:"location,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Location == "" {
+		return fmt.Sprintf("%s %s: %s", v.Severity, v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s %s %s: %s", v.Severity, v.Rule, v.Location, v.Message)
+}
+
+// Lint loads the OpenAPI document and checks it against the rules.
+func Lint(data []byte) ([]Violation, error) {
+	doc, err := utils.LoadOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+	return LintDocument(doc), nil
+}
+
+// LintFile loads the OpenAPI document of the file and checks it against the rules. The references to
+// other files are resolved relative to it, e.g. the schemas of a split output.
+func LintFile(path string) ([]Violation, error) {
+	doc, err := utils.LoadOpenAPIFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LintDocument(doc), nil
+}
+
+// LintDocument checks the document against the rules. The violation of the specification comes first,
+// the others are ordered by their location.
+func LintDocument(doc *openapi3.T) []Violation {
+	var violations []Violation
+	if err := doc.Validate(context.Background()); err != nil {
+		violations = append(violations, Violation{
+			Rule:     RuleInvalidDocument,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		})
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// operationIDs holds the location of the first operation of each operation ID
+	operationIDs := make(map[string]string)
+	for _, path := range paths {
+		operations := doc.Paths[path].Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := operations[method]
+			location := fmt.Sprintf("paths.%s.%s", path, strings.ToLower(method))
+
+			if len(op.Responses) == 0 {
+				violations = append(violations, Violation{
+					Rule:     RuleMissingResponses,
+					Severity: SeverityError,
+					Location: location,
+					Message:  "the operation declares no response",
+				})
+			}
+
+			if op.OperationID == "" {
+				violations = append(violations, Violation{
+					Rule:     RuleMissingOperationID,
+					Severity: SeverityWarning,
+					Location: location,
+					Message:  "the operation has no operationId",
+				})
+			} else if first, ok := operationIDs[op.OperationID]; ok {
+				violations = append(violations, Violation{
+					Rule:     RuleDuplicateOperationID,
+					Severity: SeverityError,
+					Location: location,
+					Message:  fmt.Sprintf("the operationId '%s' is already used by %s", op.OperationID, first),
+				})
+			} else {
+				operationIDs[op.OperationID] = location
+			}
+
+			if op.Summary == "" && op.Description == "" {
+				violations = append(violations, Violation{
+					Rule:     RuleMissingDescription,
+					Severity: SeverityWarning,
+					Location: location,
+					Message:  "the operation has neither a summary nor a description",
+				})
+			}
+			for _, parameter := range op.Parameters {
+				if parameter.Value != nil && parameter.Value.Description == "" {
+					violations = append(violations, Violation{
+						Rule:     RuleMissingDescription,
+						Severity: SeverityInfo,
+						Location: fmt.Sprintf("%s.parameters.%s", location, parameter.Value.Name),
+						Message:  "the parameter has no description",
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package thriftutils holds the helpers shared by the thrift plugins that read the thrift descriptors,
+// such as the values of the consts referenced by the openapi.enum_const and openapi.example_const annotations.
+// These are separate annotations because the openapi.property annotation is parsed as YAML, where a
+// const name such as ROLES is a plain string.
+package thriftutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/thrift_reflection"
+)
+
+// ConstYAML returns the value of the const named in the file of the field as YAML, e.g. ROLES, or
+// base.ROLES for a const of an included file. Lists, sets and maps are written in the flow style.
+func ConstYAML(field *thrift_reflection.FieldDescriptor, name string) (string, error) {
+	fd, value, err := lookupConst(field, name)
+	if err != nil {
+		return "", err
+	}
+	return constValueYAML(fd, value)
+}
+
+// ConstListYAML returns the elements of the list or set const named in the file of the field as YAML.
+func ConstListYAML(field *thrift_reflection.FieldDescriptor, name string) ([]string, error) {
+	fd, value, err := lookupConst(field, name)
+	if err != nil {
+		return nil, err
+	}
+	if value.GetType() != thrift_reflection.ConstValueType_LIST {
+		return nil, fmt.Errorf("const '%s' is not a list or set", name)
+	}
+	elements := make([]string, 0, len(value.GetValueList()))
+	for _, v := range value.GetValueList() {
+		element, err := constValueYAML(fd, v)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// lookupConst returns the value of the const named in the file of the field, along with the file
+// declaring the const, against which the identifiers of the value are resolved.
+func lookupConst(field *thrift_reflection.FieldDescriptor, name string) (*thrift_reflection.FileDescriptor, *thrift_reflection.ConstValueDescriptor, error) {
+	fd := thrift_reflection.GetGlobalDescriptor(field).LookupFD(field.GetFilepath())
+	c := fd.GetConstDescriptor(name)
+	if c == nil || c.GetValue() == nil {
+		return nil, nil, fmt.Errorf("const '%s' is not found", name)
+	}
+	return thrift_reflection.GetGlobalDescriptor(c).LookupFD(c.GetFilepath()), c.GetValue(), nil
+}
+
+func constValueYAML(fd *thrift_reflection.FileDescriptor, v *thrift_reflection.ConstValueDescriptor) (string, error) {
+	switch v.GetType() {
+	case thrift_reflection.ConstValueType_DOUBLE:
+		return strconv.FormatFloat(v.GetValueDouble(), 'g', -1, 64), nil
+	case thrift_reflection.ConstValueType_INT:
+		return strconv.FormatInt(v.GetValueInt(), 10), nil
+	case thrift_reflection.ConstValueType_STRING:
+		return strconv.Quote(v.GetValueString()), nil
+	case thrift_reflection.ConstValueType_BOOL:
+		return strconv.FormatBool(v.GetValueBool()), nil
+	case thrift_reflection.ConstValueType_LIST:
+		elements := make([]string, 0, len(v.GetValueList()))
+		for _, element := range v.GetValueList() {
+			yaml, err := constValueYAML(fd, element)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, yaml)
+		}
+		return "[" + strings.Join(elements, ", ") + "]", nil
+	case thrift_reflection.ConstValueType_MAP:
+		entries := make([]string, 0, len(v.GetValueMap()))
+		for key, value := range v.GetValueMap() {
+			keyYAML, err := constValueYAML(fd, key)
+			if err != nil {
+				return "", err
+			}
+			valueYAML, err := constValueYAML(fd, value)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, keyYAML+": "+valueYAML)
+		}
+		// The entries of a map have no order, so they are sorted to keep the output stable
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, ", ") + "}", nil
+	case thrift_reflection.ConstValueType_IDENTIFIER:
+		// Only the identifiers of other consts have a value, those of enum values don't
+		if c := fd.GetConstDescriptor(v.GetValueIdentifier()); c != nil && c.GetValue() != nil {
+			return constValueYAML(thrift_reflection.GetGlobalDescriptor(c).LookupFD(c.GetFilepath()), c.GetValue())
+		}
+		return "", fmt.Errorf("identifier '%s' is not a const", v.GetValueIdentifier())
+	}
+	return "", fmt.Errorf("unknown const value type %s", v.GetType())
+}
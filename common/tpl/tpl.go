@@ -20,7 +20,11 @@ const ServerTemplateHttp = `package swagger
 
 import (
 	"context"
+	{{- if .SplitSchemas}}
+	"embed"
+	{{- else}}
 	_ "embed"
+	{{- end}}
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
@@ -31,6 +35,11 @@ import (
 
 //go:embed openapi.yaml
 var openapiYAML []byte
+{{- if .SplitSchemas}}
+
+//go:embed schemas
+var schemasFS embed.FS
+{{- end}}
 
 func BindSwagger(h *server.Hertz) {
 	h.Use(cors.Default())
@@ -44,6 +53,18 @@ func BindSwagger(h *server.Hertz) {
 		ctx.Header("Content-Type", "application/x-yaml")
 		ctx.Write(openapiYAML)
 	})
+	{{- if .SplitSchemas}}
+
+	h.GET("/schemas/:file", func(c context.Context, ctx *app.RequestContext) {
+		data, err := schemasFS.ReadFile("schemas/" + ctx.Param("file"))
+		if err != nil {
+			ctx.NotFound()
+			return
+		}
+		ctx.Header("Content-Type", "application/x-yaml")
+		ctx.Write(data)
+	})
+	{{- end}}
 }
 `
 
@@ -51,7 +72,14 @@ const ServerTemplateRpc = `package swagger
 
 import (
 	"context"
+	{{- if .TLSCertFile}}
+	"crypto/tls"
+	{{- end}}
+	{{- if .SplitSchemas}}
+	"embed"
+	{{- else}}
 	_ "embed"
+	{{- end}}
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -61,12 +89,18 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/gopkg/cloud/metainfo"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/network"
+	{{- if .TLSCertFile}}
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	{{- end}}
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/cloudwego/kitex/client"
 	"github.com/cloudwego/kitex/client/genericclient"
@@ -77,23 +111,69 @@ import (
 	"github.com/cloudwego/kitex/pkg/remote/trans/detection"
 	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
+	"github.com/cloudwego/kitex/pkg/retry"
 	"github.com/cloudwego/kitex/pkg/transmeta"
 	"github.com/cloudwego/kitex/transport"
 	"github.com/hertz-contrib/cors"
 	"github.com/hertz-contrib/swagger"
+	{{- if eq .Registry "consul"}}
+	consul "github.com/kitex-contrib/registry-consul"
+	{{- else if eq .Registry "etcd"}}
+	etcd "github.com/kitex-contrib/registry-etcd"
+	{{- end}}
 	swaggerFiles "github.com/swaggo/files"
 )
 
 var (
 	//go:embed openapi.yaml
 	openapiYAML []byte
+	{{- if .SplitSchemas}}
+	//go:embed schemas
+	schemasFS embed.FS
+	{{- end}}
 	hertzEngine *route.Engine
 	httpReg     = regexp.MustCompile("^(?:GET |POST|PUT|DELE|HEAD|OPTI|CONN|TRAC|PATC)$")
+	{{- if .ResponseHeaders}}
+
+	// responseHeaders are the backward metadata keys returned as response headers, by method,
+	// as listed by the openapi.response_headers annotations
+	responseHeaders = map[string][]string{
+		{{- range $method, $keys := .ResponseHeaders}}
+		"{{$method}}": { {{- range $i, $key := $keys}}{{if $i}}, {{end}}"{{$key}}"{{end -}} },
+		{{- end}}
+	}
+	{{- end}}
+	genericCli  genericclient.Client
+	{{- if .TLSCertFile}}
+	tlsServer   *server.Hertz
+	{{- end}}
+
+	// shuttingDown is set once Shutdown is called, clientMu is held for reading by the proxy calls
+	// so that Shutdown closes the generic client after the calls in flight
+	shuttingDown int32
+	clientMu     sync.RWMutex
+	clientClosed bool
 )
 
 const (
-	kitexAddr = "{{.KitexAddr}}"
-	idlFile   = "{{.IdlPath}}"
+	kitexAddr   = "{{.KitexAddr}}"
+	idlFile     = "{{.IdlPath}}"
+	proxyPrefix = "{{.ProxyPrefix}}"
+	{{- if .TLSCertFile}}
+	hertzAddr   = "{{.HertzAddr}}"
+	tlsCertFile = "{{.TLSCertFile}}"
+	tlsKeyFile  = "{{.TLSKeyFile}}"
+	{{- end}}
+	{{- if .Registry}}
+
+	// registryAddr is the address of the {{.Registry}} registry resolving serviceName, the Kitex service called by the proxy
+	registryAddr = "{{.RegistryAddr}}"
+	serviceName  = "{{.ServiceName}}"
+	{{- end}}
+
+	// rpcTimeout bounds each call of the generic client, maxRetryTimes retries the failed calls, 0 disables them
+	rpcTimeout    = {{.RPCTimeout}} * time.Millisecond
+	maxRetryTimes = {{.MaxRetryTimes}}
 )
 
 type MixTransHandlerFactory struct {
@@ -128,6 +208,18 @@ func (m MixTransHandlerFactory) NewTransHandler(opt *remote.ServerOption) (remot
 	return &transHandler{ServerTransHandler: kitexOrigin}, nil
 }
 
+// GracefulShutdown closes the connections of the Kitex server gracefully, then shuts the proxy down.
+func (t *transHandler) GracefulShutdown(ctx context.Context) error {
+	var err error
+	if g, ok := t.ServerTransHandler.(remote.GracefulShutdown); ok {
+		err = g.GracefulShutdown(ctx)
+	}
+	if shutdownErr := Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
 func (t *transHandler) OnRead(ctx context.Context, conn net.Conn) error {
 	c, ok := conn.(network.Conn)
 	if ok {
@@ -149,17 +241,86 @@ func StartServer() {
 	h := server.Default()
 	h.Use(cors.Default())
 
-	cli := initializeGenericClient()
+	cli, err := initializeGenericClient()
+	if err != nil {
+		hlog.Errorf("Failed to initialize generic client: %v", err)
+	}
+	genericCli = cli
+	setupHealthRoutes(h, cli)
 	setupSwaggerRoutes(h)
 	setupProxyRoutes(h, cli)
 
-	hlog.Info("Swagger UI is available at: http://" + kitexAddr + "/swagger/index.html")
-	err := h.Engine.Init()
+	hlog.Info("Swagger UI is available at: http://" + getAddr("KITEX_ADDR", kitexAddr) + "/swagger/index.html")
+	err = h.Engine.Init()
 	if err != nil {
 		panic(err)
 	}
 
 	hertzEngine = h.Engine
+	{{- if .TLSCertFile}}
+
+	go startTLSServer(cli)
+	{{- end}}
+}
+{{- if .TLSCertFile}}
+
+// startTLSServer serves the Swagger UI and the proxy over HTTPS on hertzAddr.
+func startTLSServer(cli genericclient.Client) {
+	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		hlog.Fatal("Failed to load TLS certificate:", err)
+	}
+
+	h := server.Default(
+		server.WithHostPorts(getAddr("HERTZ_ADDR", hertzAddr)),
+		server.WithTLS(&tls.Config{Certificates: []tls.Certificate{cert}}),
+		server.WithTransport(standard.NewTransporter),
+	)
+	h.Use(cors.Default())
+
+	setupHealthRoutes(h, cli)
+	setupSwaggerRoutes(h)
+	setupProxyRoutes(h, cli)
+
+	hlog.Info("Swagger UI is available at: https://" + getAddr("HERTZ_ADDR", hertzAddr) + "/swagger/index.html")
+	tlsServer = h
+	if err := h.Run(); err != nil && atomic.LoadInt32(&shuttingDown) == 0 {
+		hlog.Errorf("Failed to run TLS server: %v", err)
+	}
+}
+{{- end}}
+
+// Shutdown gracefully stops the proxy: it shuts the HTTPS server down if any, then closes the
+// generic client once the proxy calls in flight are done. It is called by the Kitex server when it
+// shuts down gracefully, e.g. on SIGINT or SIGTERM.
+func Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&shuttingDown, 0, 1) {
+		return nil
+	}
+	var err error
+	{{- if .TLSCertFile}}
+	if tlsServer != nil {
+		err = tlsServer.Shutdown(ctx)
+	}
+	{{- end}}
+
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	clientClosed = true
+	if genericCli != nil {
+		if closeErr := genericCli.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// getAddr returns the address set in the environment variable, falling back to the generated one.
+func getAddr(env, addr string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return addr
 }
 
 func findThriftFile(fileName string) (string, error) {
@@ -207,31 +368,81 @@ func findThriftFile(fileName string) (string, error) {
 	return "", errors.New("thrift file not found: " + fileName)
 }
 
-func initializeGenericClient() genericclient.Client {
+func initializeGenericClient() (genericclient.Client, error) {
 	thriftFile, err := findThriftFile(idlFile)
 	if err != nil {
-		hlog.Fatal("Failed to locate Thrift file:", err)
+		return nil, fmt.Errorf("failed to locate Thrift file: %w", err)
 	}
 
 	p, err := generic.NewThriftFileProviderWithDynamicGo(thriftFile)
 	if err != nil {
-		hlog.Fatal("Failed to create ThriftFileProvider:", err)
+		return nil, fmt.Errorf("failed to create ThriftFileProvider: %w", err)
 	}
 
 	g, err := generic.JSONThriftGeneric(p)
 	if err != nil {
-		hlog.Fatal("Failed to create JsonThriftGeneric:", err)
+		return nil, fmt.Errorf("failed to create JsonThriftGeneric: %w", err)
 	}
 	var opts []client.Option
 	opts = append(opts, client.WithTransportProtocol(transport.TTHeader))
 	opts = append(opts, client.WithMetaHandler(transmeta.ClientTTHeaderHandler))
-	opts = append(opts, client.WithHostPorts(kitexAddr))
-	cli, err := genericclient.NewClient("swagger", g, opts...)
+	{{- if eq .Registry "consul"}}
+	r, err := consul.NewConsulResolver(getAddr("REGISTRY_ADDR", registryAddr))
 	if err != nil {
-		hlog.Fatal("Failed to create generic client:", err)
+		return nil, fmt.Errorf("failed to create consul resolver: %w", err)
 	}
+	opts = append(opts, client.WithResolver(r))
+	{{- else if eq .Registry "etcd"}}
+	r, err := etcd.NewEtcdResolver(strings.Split(getAddr("REGISTRY_ADDR", registryAddr), ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd resolver: %w", err)
+	}
+	opts = append(opts, client.WithResolver(r))
+	{{- else}}
+	opts = append(opts, client.WithHostPorts(getAddr("KITEX_ADDR", kitexAddr)))
+	{{- end}}
+	if rpcTimeout > 0 {
+		opts = append(opts, client.WithRPCTimeout(rpcTimeout))
+	}
+	if maxRetryTimes > 0 {
+		fp := retry.NewFailurePolicy()
+		fp.WithMaxRetryTimes(maxRetryTimes)
+		opts = append(opts, client.WithFailureRetry(fp))
+	}
+	cli, err := genericclient.NewClient({{if .Registry}}serviceName{{else}}"swagger"{{end}}, g, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generic client: %w", err)
+	}
+
+	return cli, nil
+}
 
-	return cli
+// setupHealthRoutes registers the liveness probe /healthz, and the readiness probe /readyz which
+// fails while the generic client calling the Kitex service is not initialized.
+func setupHealthRoutes(h *server.Hertz, cli genericclient.Client) {
+	h.GET("/healthz", func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
+
+	h.GET("/readyz", func(c context.Context, ctx *app.RequestContext) {
+		if atomic.LoadInt32(&shuttingDown) == 1 {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "shutting down",
+			})
+			return
+		}
+		if cli == nil {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "generic client not initialized",
+			})
+			return
+		}
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
 }
 
 func setupSwaggerRoutes(h *server.Hertz) {
@@ -241,10 +452,36 @@ func setupSwaggerRoutes(h *server.Hertz) {
 		ctx.Header("Content-Type", "application/x-yaml")
 		ctx.Write(openapiYAML)
 	})
+	{{- if .SplitSchemas}}
+
+	h.GET("/schemas/:file", func(c context.Context, ctx *app.RequestContext) {
+		data, err := schemasFS.ReadFile("schemas/" + ctx.Param("file"))
+		if err != nil {
+			ctx.NotFound()
+			return
+		}
+		ctx.Header("Content-Type", "application/x-yaml")
+		ctx.Write(data)
+	})
+	{{- end}}
 }
 
+// setupProxyRoutes routes the methods under proxyPrefix to the generic client, e.g. /api/Method,
+// so that they don't collide with the routes of the Swagger UI.
 func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
-	h.Any("/*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+	h.Any(proxyPrefix+"*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+		if cli == nil {
+			handleError(ctx, "generic client not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		clientMu.RLock()
+		defer clientMu.RUnlock()
+		if clientClosed {
+			handleError(ctx, "generic client closed", http.StatusServiceUnavailable)
+			return
+		}
+
 		serviceMethod := ctx.Param("ServiceMethod")
 		if serviceMethod == "" {
 			handleError(ctx, "ServiceMethod not provided", http.StatusBadRequest)
@@ -290,6 +527,13 @@ func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
 		for key, value := range m {
 			result[key] = value
 		}
+		{{- if .ResponseHeaders}}
+		for _, key := range responseHeaders[serviceMethod] {
+			if value, ok := m[key]; ok {
+				ctx.Response.Header.Set(key, value)
+			}
+		}
+		{{- end}}
 
 		respBody, err := json.Marshal(result)
 		if err != nil {
@@ -325,6 +569,9 @@ const ServerTemplateRpcPb = `package swagger
 
 import (
 	"context"
+	{{- if .TLSCertFile}}
+	"crypto/tls"
+	{{- end}}
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -335,6 +582,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/gopkg/cloud/metainfo"
 	"github.com/cloudwego/dynamicgo/proto"
@@ -342,6 +592,9 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/network"
+	{{- if .TLSCertFile}}
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	{{- end}}
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/cloudwego/kitex/client"
 	"github.com/cloudwego/kitex/client/genericclient"
@@ -352,10 +605,16 @@ import (
 	"github.com/cloudwego/kitex/pkg/remote/trans/detection"
 	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
+	"github.com/cloudwego/kitex/pkg/retry"
 	"github.com/cloudwego/kitex/pkg/transmeta"
 	"github.com/cloudwego/kitex/transport"
 	"github.com/hertz-contrib/cors"
 	"github.com/hertz-contrib/swagger"
+	{{- if eq .Registry "consul"}}
+	consul "github.com/kitex-contrib/registry-consul"
+	{{- else if eq .Registry "etcd"}}
+	etcd "github.com/kitex-contrib/registry-etcd"
+	{{- end}}
 	swaggerFiles "github.com/swaggo/files"
 )
 
@@ -364,11 +623,37 @@ var (
 	openapiYAML []byte
 	hertzEngine *route.Engine
 	httpReg     = regexp.MustCompile("^(?:GET |POST|PUT|DELE|HEAD|OPTI|CONN|TRAC|PATC)$")
+	genericCli  genericclient.Client
+	{{- if .TLSCertFile}}
+	tlsServer   *server.Hertz
+	{{- end}}
+
+	// shuttingDown is set once Shutdown is called, clientMu is held for reading by the proxy calls
+	// so that Shutdown closes the generic client after the calls in flight
+	shuttingDown int32
+	clientMu     sync.RWMutex
+	clientClosed bool
 )
 
 const (
-	kitexAddr = "{{.KitexAddr}}"
-	idlFile   = "{{.IdlPath}}"
+	kitexAddr   = "{{.KitexAddr}}"
+	idlFile     = "{{.IdlPath}}"
+	proxyPrefix = "{{.ProxyPrefix}}"
+	{{- if .TLSCertFile}}
+	hertzAddr   = "{{.HertzAddr}}"
+	tlsCertFile = "{{.TLSCertFile}}"
+	tlsKeyFile  = "{{.TLSKeyFile}}"
+	{{- end}}
+	{{- if .Registry}}
+
+	// registryAddr is the address of the {{.Registry}} registry resolving serviceName, the Kitex service called by the proxy
+	registryAddr = "{{.RegistryAddr}}"
+	serviceName  = "{{.ServiceName}}"
+	{{- end}}
+
+	// rpcTimeout bounds each call of the generic client, maxRetryTimes retries the failed calls, 0 disables them
+	rpcTimeout    = {{.RPCTimeout}} * time.Millisecond
+	maxRetryTimes = {{.MaxRetryTimes}}
 )
 
 type MixTransHandlerFactory struct {
@@ -403,6 +688,18 @@ func (m MixTransHandlerFactory) NewTransHandler(opt *remote.ServerOption) (remot
 	return &transHandler{ServerTransHandler: kitexOrigin}, nil
 }
 
+// GracefulShutdown closes the connections of the Kitex server gracefully, then shuts the proxy down.
+func (t *transHandler) GracefulShutdown(ctx context.Context) error {
+	var err error
+	if g, ok := t.ServerTransHandler.(remote.GracefulShutdown); ok {
+		err = g.GracefulShutdown(ctx)
+	}
+	if shutdownErr := Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
 func (t *transHandler) OnRead(ctx context.Context, conn net.Conn) error {
 	c, ok := conn.(network.Conn)
 	if ok {
@@ -424,17 +721,86 @@ func StartServer() {
 	h := server.Default()
 	h.Use(cors.Default())
 
-	cli := initializeGenericClient()
+	cli, err := initializeGenericClient()
+	if err != nil {
+		hlog.Errorf("Failed to initialize generic client: %v", err)
+	}
+	genericCli = cli
+	setupHealthRoutes(h, cli)
 	setupSwaggerRoutes(h)
 	setupProxyRoutes(h, cli)
 
-	hlog.Info("Swagger UI is available at: http://" + kitexAddr + "/swagger/index.html")
-	err := h.Engine.Init()
+	hlog.Info("Swagger UI is available at: http://" + getAddr("KITEX_ADDR", kitexAddr) + "/swagger/index.html")
+	err = h.Engine.Init()
 	if err != nil {
 		panic(err)
 	}
 
 	hertzEngine = h.Engine
+	{{- if .TLSCertFile}}
+
+	go startTLSServer(cli)
+	{{- end}}
+}
+{{- if .TLSCertFile}}
+
+// startTLSServer serves the Swagger UI and the proxy over HTTPS on hertzAddr.
+func startTLSServer(cli genericclient.Client) {
+	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		hlog.Fatal("Failed to load TLS certificate:", err)
+	}
+
+	h := server.Default(
+		server.WithHostPorts(getAddr("HERTZ_ADDR", hertzAddr)),
+		server.WithTLS(&tls.Config{Certificates: []tls.Certificate{cert}}),
+		server.WithTransport(standard.NewTransporter),
+	)
+	h.Use(cors.Default())
+
+	setupHealthRoutes(h, cli)
+	setupSwaggerRoutes(h)
+	setupProxyRoutes(h, cli)
+
+	hlog.Info("Swagger UI is available at: https://" + getAddr("HERTZ_ADDR", hertzAddr) + "/swagger/index.html")
+	tlsServer = h
+	if err := h.Run(); err != nil && atomic.LoadInt32(&shuttingDown) == 0 {
+		hlog.Errorf("Failed to run TLS server: %v", err)
+	}
+}
+{{- end}}
+
+// Shutdown gracefully stops the proxy: it shuts the HTTPS server down if any, then closes the
+// generic client once the proxy calls in flight are done. It is called by the Kitex server when it
+// shuts down gracefully, e.g. on SIGINT or SIGTERM.
+func Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&shuttingDown, 0, 1) {
+		return nil
+	}
+	var err error
+	{{- if .TLSCertFile}}
+	if tlsServer != nil {
+		err = tlsServer.Shutdown(ctx)
+	}
+	{{- end}}
+
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	clientClosed = true
+	if genericCli != nil {
+		if closeErr := genericCli.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// getAddr returns the address set in the environment variable, falling back to the generated one.
+func getAddr(env, addr string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return addr
 }
 
 func findPbFile(fileName string) (string, error) {
@@ -482,32 +848,82 @@ func findPbFile(fileName string) (string, error) {
 	return "", errors.New("proto file not found: " + fileName)
 }
 
-func initializeGenericClient() genericclient.Client {
+func initializeGenericClient() (genericclient.Client, error) {
 	pbFile, err := findPbFile(idlFile)
 	if err != nil {
-		hlog.Fatal("Failed to locate Proto file:", err)
+		return nil, fmt.Errorf("failed to locate Proto file: %w", err)
 	}
 
 	dOpts := proto.Options{}
 	p, err := generic.NewPbFileProviderWithDynamicGo(pbFile, context.Background(), dOpts)
 	if err != nil {
-		hlog.Fatal("Failed to create PbFileProvider:", err)
+		return nil, fmt.Errorf("failed to create PbFileProvider: %w", err)
 	}
 
 	g, err := generic.JSONPbGeneric(p)
 	if err != nil {
-		hlog.Fatal("Failed to create JsonPbGeneric:", err)
+		return nil, fmt.Errorf("failed to create JsonPbGeneric: %w", err)
 	}
 	var opts []client.Option
 	opts = append(opts, client.WithTransportProtocol(transport.TTHeader))
 	opts = append(opts, client.WithMetaHandler(transmeta.ClientTTHeaderHandler))
-	opts = append(opts, client.WithHostPorts(kitexAddr))
-	cli, err := genericclient.NewClient("swagger", g, opts...)
+	{{- if eq .Registry "consul"}}
+	r, err := consul.NewConsulResolver(getAddr("REGISTRY_ADDR", registryAddr))
 	if err != nil {
-		hlog.Fatal("Failed to create generic client:", err)
+		return nil, fmt.Errorf("failed to create consul resolver: %w", err)
 	}
+	opts = append(opts, client.WithResolver(r))
+	{{- else if eq .Registry "etcd"}}
+	r, err := etcd.NewEtcdResolver(strings.Split(getAddr("REGISTRY_ADDR", registryAddr), ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd resolver: %w", err)
+	}
+	opts = append(opts, client.WithResolver(r))
+	{{- else}}
+	opts = append(opts, client.WithHostPorts(getAddr("KITEX_ADDR", kitexAddr)))
+	{{- end}}
+	if rpcTimeout > 0 {
+		opts = append(opts, client.WithRPCTimeout(rpcTimeout))
+	}
+	if maxRetryTimes > 0 {
+		fp := retry.NewFailurePolicy()
+		fp.WithMaxRetryTimes(maxRetryTimes)
+		opts = append(opts, client.WithFailureRetry(fp))
+	}
+	cli, err := genericclient.NewClient({{if .Registry}}serviceName{{else}}"swagger"{{end}}, g, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generic client: %w", err)
+	}
+
+	return cli, nil
+}
 
-	return cli
+// setupHealthRoutes registers the liveness probe /healthz, and the readiness probe /readyz which
+// fails while the generic client calling the Kitex service is not initialized.
+func setupHealthRoutes(h *server.Hertz, cli genericclient.Client) {
+	h.GET("/healthz", func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
+
+	h.GET("/readyz", func(c context.Context, ctx *app.RequestContext) {
+		if atomic.LoadInt32(&shuttingDown) == 1 {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "shutting down",
+			})
+			return
+		}
+		if cli == nil {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "generic client not initialized",
+			})
+			return
+		}
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
 }
 
 func setupSwaggerRoutes(h *server.Hertz) {
@@ -519,8 +935,22 @@ func setupSwaggerRoutes(h *server.Hertz) {
 	})
 }
 
+// setupProxyRoutes routes the methods under proxyPrefix to the generic client, e.g. /api/Method,
+// so that they don't collide with the routes of the Swagger UI.
 func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
-	h.Any("/*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+	h.Any(proxyPrefix+"*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+		if cli == nil {
+			handleError(ctx, "generic client not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		clientMu.RLock()
+		defer clientMu.RUnlock()
+		if clientClosed {
+			handleError(ctx, "generic client closed", http.StatusServiceUnavailable)
+			return
+		}
+
 		serviceMethod := ctx.Param("ServiceMethod")
 		if serviceMethod == "" {
 			handleError(ctx, "ServiceMethod not provided", http.StatusBadRequest)
@@ -17,12 +17,29 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"gopkg.in/yaml.v3"
 )
 
 // Contains returns true if an array Contains a specified string.
@@ -66,6 +83,15 @@ func UnpackArgs(args []string, c interface{}) error {
 				return fmt.Errorf("field %s can't be assigned multi values: %v", n, values)
 			}
 			x.SetString(values[0])
+		case reflect.Int:
+			if len(values) != 1 {
+				return fmt.Errorf("field %s can't be assigned multi values: %v", n, values)
+			}
+			val, err := strconv.Atoi(values[0])
+			if err != nil {
+				return fmt.Errorf("field %s must be an integer: %v", n, err)
+			}
+			x.SetInt(int64(val))
 		case reflect.Slice:
 			if len(values) != 1 {
 				return fmt.Errorf("field %s can't be assigned multi values: %v", n, values)
@@ -162,3 +188,1070 @@ func FileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
 }
+
+// FileHeader reads the header prepended to the generated YAML files from headerFile and formats it
+// as comment lines starting with #, the lines of the file may already be commented. Blank lines are
+// kept as # so that the header stays a single comment, an empty headerFile means no header.
+func FileHeader(headerFile string) (string, error) {
+	if headerFile == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(headerFile)
+	if err != nil {
+		return "", err
+	}
+	text := strings.Trim(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimRight(line, " \t"), "#")
+		line = strings.TrimPrefix(line, " ")
+		if line == "" {
+			lines[i] = "#"
+		} else {
+			lines[i] = "# " + line
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ProxyPrefix returns the path prefix under which the rpc proxy routes the methods, starting and
+// ending with a slash, e.g. /api/ for api, or the default prefix if it is empty. Use / for the root.
+func ProxyPrefix(prefix string) string {
+	if prefix == "" {
+		prefix = consts.DefaultProxyPrefix
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return "/"
+	}
+	return "/" + prefix + "/"
+}
+
+var (
+	proxyPrefixPattern = regexp.MustCompile(`proxyPrefix\s*=\s*"(.*?)"`)
+	rootRoutePattern   = regexp.MustCompile(`h\.Any\("/\*ServiceMethod"`)
+	idlFileLinePattern = regexp.MustCompile(`(?m)^(\s*)idlFile\s*=\s*".*?"$`)
+)
+
+// UpdateProxyPrefix sets the proxy prefix of an existing swagger.go. A swagger.go generated before the
+// proxy prefix routes the methods at the root, its route is rewritten to use the prefix. It reports false
+// if the route can't be found, e.g. in a customized file.
+func UpdateProxyPrefix(content, prefix string) (string, bool) {
+	if proxyPrefixPattern.MatchString(content) {
+		return proxyPrefixPattern.ReplaceAllString(content, fmt.Sprintf(`proxyPrefix = "%s"`, prefix)), true
+	}
+	if !rootRoutePattern.MatchString(content) || !idlFileLinePattern.MatchString(content) {
+		return content, false
+	}
+	content = rootRoutePattern.ReplaceAllString(content, `h.Any(proxyPrefix+"*ServiceMethod"`)
+	content = idlFileLinePattern.ReplaceAllStringFunc(content, func(line string) string {
+		indent := idlFileLinePattern.FindStringSubmatch(line)[1]
+		return line + "\n" + indent + fmt.Sprintf(`proxyPrefix = "%s"`, prefix)
+	})
+	return content, true
+}
+
+// FileDocComment reads the doc comment of an IDL file, i.e. the first /** */ comment before its first
+// statement, and returns it as a block comment without the leading stars. A doc comment directly followed
+// by a definition, without a blank line in between, documents that definition instead, and the other
+// comments, such as license headers, are skipped.
+func FileDocComment(idlFile string) (string, error) {
+	content, err := os.ReadFile(idlFile)
+	if err != nil {
+		return "", err
+	}
+	rest := strings.ReplaceAll(string(content), "\r\n", "\n")
+	for {
+		rest = strings.TrimLeft(rest, " \t\n")
+		switch {
+		case strings.HasPrefix(rest, "/**") && !strings.HasPrefix(rest, "/**/"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return "", nil
+			}
+			comment, next := rest[3:end], strings.TrimLeft(rest[end+2:], " \t")
+			if strings.HasPrefix(next, "\n") && strings.HasPrefix(strings.TrimLeft(next[1:], " \t"), "\n") {
+				return docCommentBlock(comment), nil
+			}
+			for _, header := range []string{"namespace", "include", "cpp_include"} {
+				if strings.HasPrefix(strings.TrimLeft(next, "\n"), header) {
+					return docCommentBlock(comment), nil
+				}
+			}
+			return "", nil
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return "", nil
+			}
+			rest = rest[end+4:]
+		case strings.HasPrefix(rest, "//"), strings.HasPrefix(rest, "#"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return "", nil
+			}
+			rest = rest[end+1:]
+		default:
+			return "", nil
+		}
+	}
+}
+
+// docCommentBlock turns the text of a doc comment into a block comment without the star and the space
+// starting each line, which the comment filters would otherwise keep.
+func docCommentBlock(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		lines[i] = strings.TrimRight(strings.TrimPrefix(strings.TrimPrefix(line, "*"), " "), " \t")
+	}
+	return "/*\n" + strings.Trim(strings.Join(lines, "\n"), "\n") + "\n*/"
+}
+
+// WithBasePath prefixes the path with the base path, e.g. /service-a for service-a/, so that the documented
+// paths match the routes of a service deployed behind a gateway. An empty or / base path keeps the path.
+func WithBasePath(basePath, path string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return path
+	}
+	return "/" + basePath + "/" + strings.TrimPrefix(path, "/")
+}
+
+// ParseSunset parses the YAML of the x-sunset extension, which holds an HTTP date like the Sunset header,
+// e.g. "Wed, 31 Dec 2025 23:59:59 GMT".
+func ParseSunset(value string) (time.Time, error) {
+	var date string
+	if err := yaml.Unmarshal([]byte(value), &date); err != nil {
+		return time.Time{}, fmt.Errorf("x-sunset %s is not a string", value)
+	}
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("x-sunset '%s' is not an HTTP date such as 'Wed, 31 Dec 2025 23:59:59 GMT'", date)
+	}
+	return t, nil
+}
+
+// RPCTimeoutMillis returns the RPC timeout of the generic client of the rpc proxy in milliseconds,
+// parsed from a duration, e.g. 500ms, or from the default timeout if it is empty. 0 disables it.
+func RPCTimeoutMillis(rpcTimeout string) (int64, error) {
+	if rpcTimeout == "" {
+		rpcTimeout = consts.DefaultRPCTimeout
+	}
+	timeout, err := time.ParseDuration(rpcTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rpc timeout %q: %w", rpcTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid rpc timeout %q: must not be negative", rpcTimeout)
+	}
+	return timeout.Milliseconds(), nil
+}
+
+// ValidateRetryTimes checks the max retry times of the generic client of the rpc proxy, which Kitex
+// limits to consts.MaxRetryTimes. 0 disables the retries.
+func ValidateRetryTimes(maxRetryTimes int) error {
+	if maxRetryTimes < 0 || maxRetryTimes > consts.MaxRetryTimes {
+		return fmt.Errorf("invalid max retry times %d: must be between 0 and %d", maxRetryTimes, consts.MaxRetryTimes)
+	}
+	return nil
+}
+
+// RegistryAddr returns the address of the registry the rpc proxy resolves the Kitex service with,
+// or the default address of the registry if it is empty.
+func RegistryAddr(registry, addr string) (string, error) {
+	var defaultAddr string
+	switch registry {
+	case consts.RegistryEtcd:
+		defaultAddr = consts.DefaultEtcdRegistryAddr
+	case consts.RegistryConsul:
+		defaultAddr = consts.DefaultConsulRegistryAddr
+	default:
+		return "", fmt.Errorf("unsupported registry %q, use %q or %q", registry, consts.RegistryEtcd, consts.RegistryConsul)
+	}
+	if addr == "" {
+		return defaultAddr, nil
+	}
+	return addr, nil
+}
+
+// Extension is a specification extension set by an openapi.extension annotation, with its value in YAML.
+type Extension struct {
+	Name string
+	Yaml string
+}
+
+// ParseExtensions parses the values of openapi.extension annotations into specification extensions.
+// A value is either a single extension, x-name=value, or a JSON object of extensions, e.g.
+// {"x-go-type": {"import": "time", "type": "Time"}}. The values of the extensions are YAML or JSON,
+// so that a plain text is a string, and the names must start with x-.
+func ParseExtensions(values []string) ([]Extension, error) {
+	var extensions []Extension
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "{") {
+			var node yaml.Node
+			if err := yaml.Unmarshal([]byte(value), &node); err != nil {
+				return nil, fmt.Errorf("invalid extensions %s: %w", value, err)
+			}
+			mapping := node.Content[0]
+			for i := 0; i+1 < len(mapping.Content); i += 2 {
+				yamlValue, err := marshalExtensionValue(mapping.Content[i+1])
+				if err != nil {
+					return nil, err
+				}
+				extensions = append(extensions, Extension{Name: mapping.Content[i].Value, Yaml: yamlValue})
+			}
+			continue
+		}
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid extension %s, use x-name=value", value)
+		}
+		name, yamlValue := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(yamlValue), &node); err != nil {
+			return nil, fmt.Errorf("invalid value of extension %s: %w", name, err)
+		}
+		if len(node.Content) > 0 {
+			var err error
+			if yamlValue, err = marshalExtensionValue(node.Content[0]); err != nil {
+				return nil, err
+			}
+		}
+		extensions = append(extensions, Extension{Name: name, Yaml: yamlValue})
+	}
+	for _, extension := range extensions {
+		if !strings.HasPrefix(extension.Name, "x-") {
+			return nil, fmt.Errorf("invalid extension name %s, it must start with x-", extension.Name)
+		}
+	}
+	return extensions, nil
+}
+
+// marshalExtensionValue marshals the value of an extension in the block style of the document,
+// the JSON values are in flow style and quoted otherwise.
+func marshalExtensionValue(node *yaml.Node) (string, error) {
+	var clearStyle func(node *yaml.Node)
+	clearStyle = func(node *yaml.Node) {
+		node.Style = 0
+		for _, child := range node.Content {
+			clearStyle(child)
+		}
+	}
+	clearStyle(node)
+	bytes, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+var markdownCommentPattern = regexp.MustCompile(consts.MarkdownCommentPattern)
+
+// MarkdownComment returns the text of the thrift comments with minimal processing, so that the markdown
+// of the descriptions renders as written: the // markers and one following space are stripped from
+// line comments, blank lines are kept, and the lines of block comments lose their leading * marker,
+// or else their common indentation. Only the blank lines around the text are trimmed.
+func MarkdownComment(str string) string {
+	var lines []string
+	for _, match := range markdownCommentPattern.FindAllStringSubmatch(strings.ReplaceAll(str, "\r\n", "\n"), -1) {
+		if !strings.HasPrefix(match[0], "/*") {
+			lines = append(lines, strings.TrimPrefix(match[1], " "))
+			continue
+		}
+		blockLines := strings.Split(match[2], "\n")
+		starred := true
+		for _, line := range blockLines[1:] {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "*") {
+				starred = false
+			}
+		}
+		indent := -1
+		for _, line := range blockLines[1:] {
+			if strings.TrimSpace(line) != "" {
+				if n := len(line) - len(strings.TrimLeft(line, " \t")); indent == -1 || n < indent {
+					indent = n
+				}
+			}
+		}
+		for i, line := range blockLines {
+			switch {
+			case i == 0:
+				// The second star of a /** doc comment is part of the marker
+				if strings.HasPrefix(match[0], "/**") {
+					line = strings.TrimPrefix(line, "*")
+				}
+				line = strings.TrimSpace(line)
+			case starred:
+				line = strings.TrimLeft(line, " \t")
+				line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+			case len(line) >= indent && indent > 0:
+				line = line[indent:]
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	// Trim the blank lines around the text, keeping the trailing spaces of the lines that break them
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
+// stringExamples holds the placeholder examples of the string formats, a string of any other format
+// takes the name of its type.
+var stringExamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"byte":      "c3RyaW5n",
+	"bytes":     "c3RyaW5n",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+}
+
+// ExampleBounds holds the constraints of a schema a placeholder example has to satisfy, a nil bound
+// being unset.
+type ExampleBounds struct {
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+	MinLength        *int64
+	MaxLength        *int64
+}
+
+// ExampleForType returns the YAML of a placeholder example for a schema of the primitive type and
+// format, e.g. "string", 0 or true, moved into the bounds of the schema. It returns an empty string for
+// the other types, for binary strings, which have no meaningful placeholder, and for bounds no placeholder
+// satisfies, e.g. a maxLength shorter than the placeholder of a format.
+func ExampleForType(typ, format string, bounds ExampleBounds) string {
+	switch typ {
+	case "boolean":
+		return "true"
+	case "integer", "number":
+		if v, ok := numberExample(typ == "integer", bounds); ok {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	case "string":
+		if format == "binary" {
+			return ""
+		}
+		if example, ok := stringExample(format, bounds); ok {
+			return strconv.Quote(example)
+		}
+	}
+	return ""
+}
+
+// numberExample returns 0 moved into the bounds, to the closest integer for an integer schema.
+func numberExample(integer bool, bounds ExampleBounds) (float64, bool) {
+	v := 0.0
+	if bounds.Minimum != nil && (v < *bounds.Minimum || v == *bounds.Minimum && bounds.ExclusiveMinimum) {
+		v = *bounds.Minimum
+		if integer {
+			v = math.Ceil(v)
+		}
+		if v == *bounds.Minimum && bounds.ExclusiveMinimum {
+			v++
+		}
+	}
+	if bounds.Maximum != nil && (v > *bounds.Maximum || v == *bounds.Maximum && bounds.ExclusiveMaximum) {
+		v = *bounds.Maximum
+		if integer {
+			v = math.Floor(v)
+		}
+		if v == *bounds.Maximum && bounds.ExclusiveMaximum {
+			v--
+		}
+		// A number between close exclusive bounds
+		if !integer && bounds.Minimum != nil && v <= *bounds.Minimum {
+			v = (*bounds.Minimum + *bounds.Maximum) / 2
+		}
+	}
+	if bounds.Minimum != nil && (v < *bounds.Minimum || v == *bounds.Minimum && bounds.ExclusiveMinimum) {
+		return 0, false
+	}
+	if bounds.Maximum != nil && (v > *bounds.Maximum || v == *bounds.Maximum && bounds.ExclusiveMaximum) {
+		return 0, false
+	}
+	return v, true
+}
+
+// stringExample returns the placeholder of the format, a plain string being padded or truncated to the
+// length bounds.
+func stringExample(format string, bounds ExampleBounds) (string, bool) {
+	example, ok := stringExamples[format]
+	if !ok {
+		example = "string"
+		if bounds.MinLength != nil && int64(len(example)) < *bounds.MinLength {
+			example += strings.Repeat("s", int(*bounds.MinLength)-len(example))
+		}
+		if bounds.MaxLength != nil && int64(len(example)) > *bounds.MaxLength && *bounds.MaxLength >= 0 {
+			example = example[:*bounds.MaxLength]
+		}
+	}
+	if bounds.MinLength != nil && int64(len(example)) < *bounds.MinLength {
+		return "", false
+	}
+	if bounds.MaxLength != nil && int64(len(example)) > *bounds.MaxLength {
+		return "", false
+	}
+	return example, true
+}
+
+// OperationID holds the names an operation ID template can refer to.
+type OperationID struct {
+	Service string
+	Method  string
+}
+
+// NewOperationIDTemplate parses the template of the operation IDs, which can use .Service and
+// .Method as well as the lowerFirst and upperFirst functions. An empty text uses the default template.
+func NewOperationIDTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = consts.DefaultOperationIDTemplate
+	}
+	t, err := template.New("operationId").Funcs(template.FuncMap{
+		"lowerFirst": func(s string) string { return changeFirst(s, unicode.ToLower) },
+		"upperFirst": func(s string) string { return changeFirst(s, unicode.ToUpper) },
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = FormatOperationID(t, "Service", "Method"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FormatOperationID returns the operation ID of the method of the service formatted by the template.
+func FormatOperationID(t *template.Template, service, method string) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, OperationID{Service: service, Method: method}); err != nil {
+		return "", err
+	}
+	if sb.Len() == 0 {
+		return "", errors.New("empty operation id")
+	}
+	return sb.String(), nil
+}
+
+func changeFirst(s string, change func(rune) rune) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(change(r)) + s[size:]
+}
+
+// ValidateOpenAPI loads the OpenAPI document and validates it against the OpenAPI 3 specification.
+// The webhooks of OpenAPI 3.1 are left out, since the validation follows OpenAPI 3.0.
+func ValidateOpenAPI(data []byte) error {
+	doc, err := LoadOpenAPI(data)
+	if err != nil {
+		return err
+	}
+	return doc.Validate(context.Background())
+}
+
+// LoadOpenAPI loads the OpenAPI document with kin-openapi, leaving out the webhooks of OpenAPI 3.1
+// that it doesn't know of.
+func LoadOpenAPI(data []byte) (*openapi3.T, error) {
+	return loadOpenAPI(openapi3.NewLoader(), data, nil)
+}
+
+// LoadOpenAPIFile loads the OpenAPI document of the file like LoadOpenAPI, resolving the references
+// to other files relative to it, e.g. the schemas of a split output.
+func LoadOpenAPIFile(path string) (*openapi3.T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi document: %w", err)
+	}
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	return loadOpenAPI(loader, data, &url.URL{Path: filepath.ToSlash(path)})
+}
+
+func loadOpenAPI(loader *openapi3.Loader, data []byte, location *url.URL) (*openapi3.T, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+	if len(node.Content) > 0 && mappingValue(node.Content[0], "webhooks") != nil {
+		removeMappingKey(node.Content[0], "webhooks")
+		var err error
+		if data, err = yaml.Marshal(&node); err != nil {
+			return nil, err
+		}
+	}
+	var doc *openapi3.T
+	var err error
+	if location != nil {
+		doc, err = loader.LoadFromDataWithPath(data, location)
+	} else {
+		doc, err = loader.LoadFromData(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+	return doc, nil
+}
+
+// InlineSchemas moves the component schemas referenced exactly once in the YAML document to the
+// place of the reference. Recursive schemas and schemas referenced more than once are kept.
+func InlineSchemas(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	root := doc.Content[0]
+	components := mappingValue(root, "components")
+	schemas := mappingValue(components, "schemas")
+	if schemas == nil {
+		return data, nil
+	}
+
+	for inlined := true; inlined; {
+		inlined = false
+		refs := map[string][]*yaml.Node{}
+		collectSchemaRefs(root, refs)
+		for i := 0; i+1 < len(schemas.Content); i += 2 {
+			name, schema := schemas.Content[i].Value, schemas.Content[i+1]
+			if len(refs[name]) != 1 || schema.Kind != yaml.MappingNode {
+				continue
+			}
+			// The single reference is inside the schema itself
+			ownRefs := map[string][]*yaml.Node{}
+			collectSchemaRefs(schema, ownRefs)
+			if len(ownRefs[name]) > 0 {
+				continue
+			}
+			ref := refs[name][0]
+			content := make([]*yaml.Node, 0, len(ref.Content)+len(schema.Content))
+			content = append(content, schema.Content...)
+			for j := 0; j+1 < len(ref.Content); j += 2 {
+				if ref.Content[j].Value != "$ref" {
+					content = append(content, ref.Content[j], ref.Content[j+1])
+				}
+			}
+			ref.Content = content
+			schemas.Content = append(schemas.Content[:i], schemas.Content[i+2:]...)
+			inlined = true
+			break
+		}
+	}
+
+	// Drop the schemas and the components if they become empty
+	if len(schemas.Content) == 0 {
+		removeMappingKey(components, "schemas")
+		if len(components.Content) == 0 {
+			removeMappingKey(root, "components")
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// PruneSchemas removes the component schemas of the YAML document that are not referenced by the
+// rest of the document, directly or through other schemas.
+func PruneSchemas(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	root := doc.Content[0]
+	components := mappingValue(root, "components")
+	schemas := mappingValue(components, "schemas")
+	if schemas == nil {
+		return data, nil
+	}
+	for _, name := range unusedSchemas(root, schemas) {
+		removeMappingKey(schemas, name)
+	}
+	if len(schemas.Content) == 0 {
+		removeMappingKey(components, "schemas")
+		if len(components.Content) == 0 {
+			removeMappingKey(root, "components")
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// UnusedSchemas returns the names of the component schemas of the YAML document that PruneSchemas
+// removes, e.g. to drop the files of the unused schemas in split output mode.
+func UnusedSchemas(data []byte) ([]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	schemas := mappingValue(mappingValue(root, "components"), "schemas")
+	if schemas == nil {
+		return nil, nil
+	}
+	return unusedSchemas(root, schemas), nil
+}
+
+// unusedSchemas walks the references from the document outside of the component schemas to the
+// schemas, and from the reached schemas to the schemas they reference.
+func unusedSchemas(root, schemas *yaml.Node) []string {
+	refs := map[string][]*yaml.Node{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "components" {
+			collectSchemaRefs(root.Content[i+1], refs)
+			continue
+		}
+		components := root.Content[i+1]
+		for j := 0; j+1 < len(components.Content); j += 2 {
+			if components.Content[j].Value != "schemas" {
+				collectSchemaRefs(components.Content[j+1], refs)
+			}
+		}
+	}
+	var queue []string
+	for name := range refs {
+		queue = append(queue, name)
+	}
+	reached := map[string]bool{}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if reached[name] {
+			continue
+		}
+		reached[name] = true
+		if schema := mappingValue(schemas, name); schema != nil {
+			schemaRefs := map[string][]*yaml.Node{}
+			collectSchemaRefs(schema, schemaRefs)
+			for ref := range schemaRefs {
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	var unused []string
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		if !reached[schemas.Content[i].Value] {
+			unused = append(unused, schemas.Content[i].Value)
+		}
+	}
+	return unused
+}
+
+// collectSchemaRefs collects the mapping nodes holding a reference to a component schema by schema name.
+func collectSchemaRefs(node *yaml.Node, refs map[string][]*yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && strings.HasPrefix(value.Value, consts.ComponentSchemaPrefix) {
+				name := strings.TrimPrefix(value.Value, consts.ComponentSchemaPrefix)
+				refs[name] = append(refs[name], node)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		collectSchemaRefs(child, refs)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// ConvertToOpenAPI31 converts the schemas of the YAML document from OpenAPI 3.0 to OpenAPI 3.1,
+// which follows JSON Schema: nullable becomes a 'null' type, boolean exclusive bounds become the
+// bounds themselves and examples become lists. The openapi field of the document is kept.
+func ConvertToOpenAPI31(data []byte) ([]byte, error) {
+	return convertYAML(data, convertSchemasToOpenAPI31)
+}
+
+// ConvertSchemaToOpenAPI31 converts a YAML schema like ConvertToOpenAPI31, e.g. a split schema file.
+func ConvertSchemaToOpenAPI31(data []byte) ([]byte, error) {
+	return convertYAML(data, convertSchemaToOpenAPI31)
+}
+
+func convertYAML(data []byte, convert func(node *yaml.Node)) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	convert(doc.Content[0])
+	return yaml.Marshal(&doc)
+}
+
+// convertSchemasToOpenAPI31 converts the schemas found in the node, i.e. the values of the schema
+// keys and the component schemas.
+func convertSchemasToOpenAPI31(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			convertSchemasToOpenAPI31(child)
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch {
+		case key == "schema":
+			convertSchemaToOpenAPI31(value)
+		case key == "components" && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if value.Content[j].Value == "schemas" {
+					for k := 1; k < len(value.Content[j+1].Content); k += 2 {
+						convertSchemaToOpenAPI31(value.Content[j+1].Content[k])
+					}
+				} else {
+					convertSchemasToOpenAPI31(value.Content[j+1])
+				}
+			}
+		default:
+			convertSchemasToOpenAPI31(value)
+		}
+	}
+}
+
+// convertSchemaToOpenAPI31 converts the schema node and its subschemas.
+func convertSchemaToOpenAPI31(schema *yaml.Node) {
+	if schema.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(schema.Content); i += 2 {
+		key, value := schema.Content[i].Value, schema.Content[i+1]
+		switch key {
+		case "properties":
+			for j := 1; j < len(value.Content); j += 2 {
+				convertSchemaToOpenAPI31(value.Content[j])
+			}
+		case "items", "additionalProperties", "not":
+			convertSchemaToOpenAPI31(value)
+		case "allOf", "anyOf", "oneOf":
+			for _, child := range value.Content {
+				convertSchemaToOpenAPI31(child)
+			}
+		}
+	}
+
+	if nullable := mappingValue(schema, "nullable"); nullable != nil {
+		removeMappingKey(schema, "nullable")
+		if nullable.Value == "true" {
+			null := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "null"}
+			if typ := mappingValue(schema, "type"); typ != nil && typ.Kind == yaml.ScalarNode {
+				*typ = yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: typ.Value}, null}}
+			} else if ref := mappingValue(schema, "$ref"); ref != nil {
+				// Siblings of $ref are allowed in 3.1, but the 'null' type would conflict with the referenced type
+				removeMappingKey(schema, "$ref")
+				refSchema := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: "$ref"}, ref}}
+				nullSchema := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: "type"}, null}}
+				schema.Content = append(schema.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Value: "anyOf"},
+					&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{refSchema, nullSchema}})
+			} else if typ == nil {
+				schema.Content = append(schema.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "type"}, null)
+			}
+		}
+	}
+
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		exclusive := mappingValue(schema, "exclusive"+bound)
+		if exclusive == nil || exclusive.Tag != "!!bool" {
+			continue
+		}
+		value := mappingValue(schema, strings.ToLower(bound))
+		removeMappingKey(schema, "exclusive"+bound)
+		if exclusive.Value == "true" && value != nil {
+			removeMappingKey(schema, strings.ToLower(bound))
+			schema.Content = append(schema.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "exclusive" + bound}, value)
+		}
+	}
+
+	if example := mappingValue(schema, "example"); example != nil {
+		removeMappingKey(schema, "example")
+		schema.Content = append(schema.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "examples"},
+			&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{example}})
+	}
+}
+
+// swagger2KeyOrder is the order of the top-level keys of a Swagger 2.0 document, the
+// specification extensions follow them.
+var swagger2KeyOrder = []string{
+	"swagger", "info", "host", "basePath", "schemes", "consumes", "produces", "paths", "definitions",
+	"parameters", "responses", "securityDefinitions", "security", "tags", "externalDocs",
+}
+
+// ConvertToSwagger2 converts the YAML document from OpenAPI 3.0 to Swagger 2.0 for the tools that only
+// read Swagger 2.0: the component schemas become definitions, the request bodies become body or formData
+// parameters, the media types of the request bodies become consumes and the first server becomes the
+// host and basePath. The parts without a Swagger 2.0 equivalent, e.g. callbacks and links, are dropped.
+func ConvertToSwagger2(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	doc, err := LoadOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+	// The host of a server can't be parsed with its variables, so they are replaced with their defaults
+	for _, server := range doc.Servers {
+		for name, variable := range server.Variables {
+			server.URL = strings.ReplaceAll(server.URL, "{"+name+"}", variable.Default)
+		}
+	}
+	prepareSwagger2(doc)
+	// kin-openapi takes the binary properties out of the schemas as file parameters, so they are put back
+	binaryProperties := map[schemaProperty]*openapi3.SchemaRef{}
+	for _, schema := range doc.Components.Schemas {
+		collectBinaryProperties(schema, binaryProperties)
+	}
+	doc2, err := openapi2conv.FromV3(doc)
+	if err != nil {
+		return nil, err
+	}
+	for property, schema := range binaryProperties {
+		property.parent.Properties[property.name] = schema
+	}
+	setCollectionFormats(doc2, doc)
+	jsonData, err := json.Marshal(doc2)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML, so the converted document is read back as YAML to restore the block style,
+	// the order of the top-level keys and the head comment of the document
+	var converted yaml.Node
+	if err = yaml.Unmarshal(jsonData, &converted); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&converted)
+	root := converted.Content[0]
+	content := make([]*yaml.Node, 0, len(root.Content))
+	for _, key := range swagger2KeyOrder {
+		if value := mappingValue(root, key); value != nil {
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		}
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if strings.HasPrefix(root.Content[i].Value, "x-") {
+			content = append(content, root.Content[i], root.Content[i+1])
+		}
+	}
+	root.Content = content
+	converted.HeadComment = node.HeadComment
+	if len(node.Content) > 0 && node.Content[0].HeadComment != "" {
+		root.HeadComment = node.Content[0].HeadComment
+	}
+	return yaml.Marshal(&converted)
+}
+
+// prepareSwagger2 adapts the parameters and forms of the document to Swagger 2.0 before the conversion.
+// Only body parameters can refer to a schema, so the schemas of the other parameters are inlined, and the
+// other parameters and the fields of forms are primitive, so their objects become strings. Swagger 2.0
+// has no cookie parameters, so they are dropped.
+func prepareSwagger2(doc *openapi3.T) {
+	for _, item := range doc.Paths {
+		item.Parameters = withoutCookieParameters(item.Parameters)
+		for _, parameter := range item.Parameters {
+			inlineParameterSchema(parameter)
+		}
+		for _, op := range item.Operations() {
+			op.Parameters = withoutCookieParameters(op.Parameters)
+			for _, parameter := range op.Parameters {
+				inlineParameterSchema(parameter)
+			}
+			if op.RequestBody == nil || op.RequestBody.Value == nil {
+				continue
+			}
+			for contentType, mediaType := range op.RequestBody.Value.Content {
+				if contentType == consts.ContentTypeFormURLEncoded || contentType == consts.ContentTypeFormMultipart {
+					flattenFormSchema(mediaType)
+				}
+			}
+		}
+	}
+	for name, parameter := range doc.Components.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInCookie {
+			delete(doc.Components.Parameters, name)
+			continue
+		}
+		inlineParameterSchema(parameter)
+	}
+}
+
+func withoutCookieParameters(parameters openapi3.Parameters) openapi3.Parameters {
+	var kept openapi3.Parameters
+	for _, parameter := range parameters {
+		if parameter.Value == nil || parameter.Value.In != openapi3.ParameterInCookie {
+			kept = append(kept, parameter)
+		}
+	}
+	return kept
+}
+
+// schemaProperty is a property of a schema.
+type schemaProperty struct {
+	parent *openapi3.Schema
+	name   string
+}
+
+// collectBinaryProperties collects the binary properties of the schema and of its inline subschemas.
+func collectBinaryProperties(schema *openapi3.SchemaRef, properties map[schemaProperty]*openapi3.SchemaRef) {
+	if schema == nil || schema.Ref != "" || schema.Value == nil {
+		return
+	}
+	for name, property := range schema.Value.Properties {
+		if property.Ref == "" && property.Value != nil && property.Value.Type == "string" && property.Value.Format == "binary" {
+			properties[schemaProperty{parent: schema.Value, name: name}] = property
+			continue
+		}
+		collectBinaryProperties(property, properties)
+	}
+	collectBinaryProperties(schema.Value.Items, properties)
+	collectBinaryProperties(schema.Value.AdditionalProperties.Schema, properties)
+	for _, subschema := range schema.Value.AllOf {
+		collectBinaryProperties(subschema, properties)
+	}
+}
+
+func inlineParameterSchema(parameter *openapi3.ParameterRef) {
+	if parameter.Value == nil || parameter.Value.Schema == nil || parameter.Value.Schema.Value == nil {
+		return
+	}
+	schema := *parameter.Value.Schema.Value
+	// A map parameter is sent as a JSON string
+	if schema.Type == consts.SchemaObjectType {
+		schema = openapi3.Schema{Type: "string", Description: schema.Description}
+	}
+	if schema.Items != nil && schema.Items.Value != nil {
+		schema.Items = &openapi3.SchemaRef{Value: schema.Items.Value}
+	}
+	parameter.Value.Schema = &openapi3.SchemaRef{Value: &schema}
+}
+
+func flattenFormSchema(mediaType *openapi3.MediaType) {
+	if mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return
+	}
+	form := mediaType.Schema.Value
+	properties := make(openapi3.Schemas, len(form.Properties))
+	for name, property := range form.Properties {
+		if property.Value == nil {
+			continue
+		}
+		field := *property.Value
+		// A reference marked as deprecated is wrapped with allOf
+		if field.Type == "" && len(field.AllOf) == 1 && field.AllOf[0].Value != nil {
+			description := field.Description
+			field = *field.AllOf[0].Value
+			field.Description = description
+		}
+		if field.Type == "" || field.Type == "object" {
+			field = openapi3.Schema{Type: "string", Description: field.Description}
+		}
+		if field.Items != nil && field.Items.Value != nil {
+			field.Items = &openapi3.SchemaRef{Value: field.Items.Value}
+		}
+		// kin-openapi reads whether a form field is required from the schema of the field
+		field.Required = nil
+		if Contains(form.Required, name) {
+			field.Required = []string{name}
+		}
+		properties[name] = &openapi3.SchemaRef{Value: &field}
+	}
+	mediaType.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: consts.SchemaObjectType, Properties: properties}}
+}
+
+// setCollectionFormats sets the collection formats of the array parameters from the styles of the
+// OpenAPI 3 parameters, which kin-openapi leaves out, e.g. multi for the repeated query parameters.
+func setCollectionFormats(doc2 *openapi2.T, doc *openapi3.T) {
+	for path, item := range doc2.Paths {
+		for method, op := range item.Operations() {
+			var parameters openapi3.Parameters
+			if item3 := doc.Paths[path]; item3 != nil && item3.GetOperation(method) != nil {
+				parameters = item3.GetOperation(method).Parameters
+			}
+			for _, parameter := range op.Parameters {
+				if parameter.Type != "array" {
+					continue
+				}
+				if parameter.In == "formData" {
+					parameter.CollectionFormat = "multi"
+				} else if parameter3 := parameters.GetByInAndName(parameter.In, parameter.Name); parameter3 != nil {
+					parameter.CollectionFormat = collectionFormat(parameter3)
+				}
+			}
+		}
+	}
+	for name, parameter := range doc2.Parameters {
+		if parameter3 := doc.Components.Parameters[name]; parameter.Type == "array" && parameter3 != nil && parameter3.Value != nil {
+			parameter.CollectionFormat = collectionFormat(parameter3.Value)
+		}
+	}
+}
+
+func collectionFormat(parameter *openapi3.Parameter) string {
+	method, err := parameter.SerializationMethod()
+	if err != nil {
+		return ""
+	}
+	switch method.Style {
+	case openapi3.SerializationForm:
+		if method.Explode {
+			return "multi"
+		}
+	case openapi3.SerializationSpaceDelimited:
+		return "ssv"
+	case openapi3.SerializationPipeDelimited:
+		return "pipes"
+	}
+	return ""
+}
+
+// resetYAMLStyle drops the flow and quoted styles of the nodes read from JSON, strings that would
+// be read as another type are still quoted when marshaled.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
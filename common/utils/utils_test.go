@@ -0,0 +1,238 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const pruneDocument = `openapi: 3.0.3
+paths:
+    /a:
+        get:
+            responses:
+                "200":
+                    description: A
+                    content:
+                        application/json:
+                            schema:
+                                $ref: '#/components/schemas/A'
+components:
+    schemas:
+        A:
+            type: object
+            properties:
+                b:
+                    allOf:
+                        - $ref: '#/components/schemas/B'
+        B:
+            type: object
+            properties:
+                list:
+                    type: array
+                    items:
+                        $ref: '#/components/schemas/D'
+            additionalProperties:
+                $ref: '#/components/schemas/E'
+        C:
+            type: object
+            properties:
+                f:
+                    $ref: '#/components/schemas/F'
+        D:
+            type: string
+        E:
+            type: integer
+        F:
+            type: boolean
+`
+
+func TestPruneSchemas(t *testing.T) {
+	pruned, err := PruneSchemas([]byte(pruneDocument))
+	if err != nil {
+		t.Fatalf("PruneSchemas: %v", err)
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(pruned, &doc); err != nil {
+		t.Fatalf("unmarshal pruned document: %v", err)
+	}
+	var kept []string
+	for name := range doc.Components.Schemas {
+		kept = append(kept, name)
+	}
+	sort.Strings(kept)
+	// B is only referenced in an allOf, D in items and E in additionalProperties, F only by the unused C.
+	if want := []string{"A", "B", "D", "E"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept schemas = %v, want %v", kept, want)
+	}
+}
+
+func TestUnusedSchemas(t *testing.T) {
+	unused, err := UnusedSchemas([]byte(pruneDocument))
+	if err != nil {
+		t.Fatalf("UnusedSchemas: %v", err)
+	}
+	sort.Strings(unused)
+	if want := []string{"C", "F"}; !reflect.DeepEqual(unused, want) {
+		t.Errorf("unused schemas = %v, want %v", unused, want)
+	}
+}
+
+func TestUpdateProxyPrefix(t *testing.T) {
+	const root = `const (
+	kitexAddr = "127.0.0.1:8888"
+	idlFile   = "hello.thrift"
+)
+
+func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
+	h.Any("/*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+`
+	const prefixed = `const (
+	kitexAddr = "127.0.0.1:8888"
+	idlFile   = "hello.thrift"
+	proxyPrefix = "/rpc/"
+)
+
+func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
+	h.Any(proxyPrefix+"*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+`
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		ok      bool
+	}{
+		{name: "root route", content: root, want: prefixed, ok: true},
+		{name: "proxy prefix", content: strings.Replace(prefixed, "/rpc/", "/api/", 1), want: prefixed, ok: true},
+		{name: "custom route", content: strings.Replace(root, `h.Any("/*ServiceMethod"`, `h.POST("/:method"`, 1), ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := UpdateProxyPrefix(tt.content, "/rpc/")
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+		} else if ok && got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExampleForType(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	n := func(v int64) *int64 { return &v }
+	tests := []struct {
+		typ, format string
+		bounds      ExampleBounds
+		want        string
+	}{
+		{typ: "integer", want: "0"},
+		{typ: "integer", bounds: ExampleBounds{Minimum: f(1)}, want: "1"},
+		{typ: "integer", bounds: ExampleBounds{Minimum: f(0), ExclusiveMinimum: true}, want: "1"},
+		{typ: "integer", bounds: ExampleBounds{Minimum: f(0.5)}, want: "1"},
+		{typ: "integer", bounds: ExampleBounds{Maximum: f(-3), ExclusiveMaximum: true}, want: "-4"},
+		{typ: "integer", bounds: ExampleBounds{Minimum: f(1.2), Maximum: f(1.8)}, want: ""},
+		{typ: "number", bounds: ExampleBounds{Minimum: f(-10), Maximum: f(10)}, want: "0"},
+		{typ: "number", bounds: ExampleBounds{Minimum: f(1), Maximum: f(1.5), ExclusiveMinimum: true, ExclusiveMaximum: true}, want: "1.25"},
+		{typ: "string", bounds: ExampleBounds{MinLength: n(8)}, want: `"stringss"`},
+		{typ: "string", bounds: ExampleBounds{MaxLength: n(3)}, want: `"str"`},
+		{typ: "string", format: "uuid", bounds: ExampleBounds{MaxLength: n(10)}, want: ""},
+		{typ: "string", format: "binary", want: ""},
+	}
+	for _, tt := range tests {
+		if got := ExampleForType(tt.typ, tt.format, tt.bounds); got != tt.want {
+			t.Errorf("ExampleForType(%s, %s, %+v) = %s, want %s", tt.typ, tt.format, tt.bounds, got, tt.want)
+		}
+	}
+}
+
+func TestFileDocComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "doc comment before the namespace",
+			content: "/** The user service. */\nnamespace go user\n",
+			want:    "/*\nThe user service.\n*/",
+		},
+		{
+			name:    "license header",
+			content: "/*\n * Copyright 2024 CloudWeGo Authors\n */\n\n// user.thrift\n/**\n * The user service.\n *\n * It manages the users.\n */\n\nstruct User {}\n",
+			want:    "/*\nThe user service.\n\nIt manages the users.\n*/",
+		},
+		{
+			name:    "doc comment of a definition",
+			content: "/** A user. */\nstruct User {}\n",
+			want:    "",
+		},
+		{
+			name:    "empty block comment",
+			content: "/**/\n/** The user service. */\n\nstruct User {}\n",
+			want:    "/*\nThe user service.\n*/",
+		},
+		{
+			name:    "license header only",
+			content: "/*\n * Copyright 2024 CloudWeGo Authors\n */\nnamespace go user\n",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "user.thrift")
+		if err := os.WriteFile(file, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := FileDocComment(file)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestConvertToSwagger2 converts testdata/swagger2/openapi.yaml and compares it with swagger2.yaml. The
+// document covers the server variables becoming the host and basePath, the body and formData parameters,
+// the collection formats, the dropped cookie parameter, the binary property kept in a definition and
+// the nullable property.
+func TestConvertToSwagger2(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "swagger2", "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "swagger2", "swagger2.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConvertToSwagger2(data)
+	if err != nil {
+		t.Fatalf("ConvertToSwagger2: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -4,9 +4,21 @@ go 1.18
 
 require (
 	github.com/apache/thrift v0.13.0
+	github.com/cloudwego/thriftgo v0.3.15
+	github.com/getkin/kin-openapi v0.118.0
 	github.com/google/gnostic-models v0.6.8
 	google.golang.org/protobuf v1.34.2
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/golang/protobuf v1.5.4 // indirect
+require (
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
+	github.com/invopop/yaml v0.1.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
+	github.com/perimeterx/marshmallow v1.1.4 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
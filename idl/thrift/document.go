@@ -59,11 +59,8 @@ func (m *AdditionalPropertiesItem) ToRawInfo() *yaml.Node {
 		return v0.ToRawInfo()
 	}
 	// {Name:boolean Type:bool StringEnumValues:[] MapType: Repeated:false Pattern: Implicit:false Description:}
-	v1 := m.Boolean
-	if v1 {
-		return compiler.NewScalarNodeForBool(v1)
-	}
-	return compiler.NewNullNode()
+	// Without a schema the item is the boolean form, false included, e.g. for closed schemas
+	return compiler.NewScalarNodeForBool(m.Boolean)
 }
 
 // ToRawInfo returns a description of Any suitable for JSON or YAML export.
@@ -289,6 +286,10 @@ func (m *Document) ToRawInfo() *yaml.Node {
 	// always include this required field.
 	info.Content = append(info.Content, compiler.NewScalarNodeForString("paths"))
 	info.Content = append(info.Content, m.Paths.ToRawInfo())
+	if m.Webhooks != nil && len(m.Webhooks.Path) != 0 {
+		info.Content = append(info.Content, compiler.NewScalarNodeForString("webhooks"))
+		info.Content = append(info.Content, m.Webhooks.ToRawInfo())
+	}
 	if m.Components != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("components"))
 		info.Content = append(info.Content, m.Components.ToRawInfo())
@@ -1116,7 +1117,8 @@ func (m *Operation) ToRawInfo() *yaml.Node {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("deprecated"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForBool(m.Deprecated))
 	}
-	if len(m.Security) != 0 {
+	// An empty security list is kept, it removes the security of the document from the operation
+	if m.Security != nil {
 		items := compiler.NewSequenceNode()
 		for _, item := range m.Security {
 			items.Content = append(items.Content, item.ToRawInfo())
@@ -1560,53 +1562,53 @@ func (m *Schema) ToRawInfo() *yaml.Node {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("multipleOf"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForFloat(m.MultipleOf))
 	}
-	if m.Maximum != 0.0 {
+	if m.Maximum != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("maximum"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForFloat(m.Maximum))
+		info.Content = append(info.Content, compiler.NewScalarNodeForFloat(*m.Maximum))
 	}
 	if m.ExclusiveMaximum {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("exclusiveMaximum"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForBool(m.ExclusiveMaximum))
 	}
-	if m.Minimum != 0.0 {
+	if m.Minimum != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("minimum"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForFloat(m.Minimum))
+		info.Content = append(info.Content, compiler.NewScalarNodeForFloat(*m.Minimum))
 	}
 	if m.ExclusiveMinimum {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("exclusiveMinimum"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForBool(m.ExclusiveMinimum))
 	}
-	if m.MaxLength != 0 {
+	if m.MaxLength != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("maxLength"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MaxLength))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MaxLength))
 	}
-	if m.MinLength != 0 {
+	if m.MinLength != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("minLength"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MinLength))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MinLength))
 	}
 	if m.Pattern != "" {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("pattern"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForString(m.Pattern))
 	}
-	if m.MaxItems != 0 {
+	if m.MaxItems != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("maxItems"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MaxItems))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MaxItems))
 	}
-	if m.MinItems != 0 {
+	if m.MinItems != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("minItems"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MinItems))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MinItems))
 	}
 	if m.UniqueItems {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("uniqueItems"))
 		info.Content = append(info.Content, compiler.NewScalarNodeForBool(m.UniqueItems))
 	}
-	if m.MaxProperties != 0 {
+	if m.MaxProperties != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("maxProperties"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MaxProperties))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MaxProperties))
 	}
-	if m.MinProperties != 0 {
+	if m.MinProperties != nil {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("minProperties"))
-		info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.MinProperties))
+		info.Content = append(info.Content, compiler.NewScalarNodeForInt(*m.MinProperties))
 	}
 	if len(m.Required) != 0 {
 		info.Content = append(info.Content, compiler.NewScalarNodeForString("required"))
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"github.com/google/gnostic-models/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// PropertiesExample composes an example object from the examples of the schema properties.
+// It returns nil when none of the properties has an example.
+func (m *Schema) PropertiesExample() *Any {
+	if m == nil || m.Properties == nil {
+		return nil
+	}
+	info := compiler.NewMappingNode()
+	for _, pair := range m.Properties.AdditionalProperties {
+		if pair.Value == nil || pair.Value.Schema == nil || pair.Value.Schema.Example == nil {
+			continue
+		}
+		info.Content = append(info.Content, compiler.NewScalarNodeForString(pair.Name))
+		info.Content = append(info.Content, pair.Value.Schema.Example.ToRawInfo())
+	}
+	if len(info.Content) == 0 {
+		return nil
+	}
+	bytes, err := yaml.Marshal(info)
+	if err != nil {
+		return nil
+	}
+	return &Any{Yaml: string(bytes)}
+}
@@ -14,7 +14,7 @@
  * limitations under the License.
  */
 
-// Code generated by thriftgo (0.3.14). DO NOT EDIT.
+// Code generated by thriftgo (0.3.17). DO NOT EDIT.
 
 package openapi
 
@@ -24,7 +24,8 @@ import (
 )
 
 type _ServiceOptions struct {
-	Document *Document `thrift:"document,1,required" json:"document"`
+	Document     *Document     `thrift:"document,1,required" json:"document"`
+	ExternalDocs *ExternalDocs `thrift:"external_docs,2,required" json:"external_docs"`
 }
 
 func New_ServiceOptions() *_ServiceOptions {
@@ -43,19 +44,34 @@ func (p *_ServiceOptions) GetDocument() (v *Document) {
 	return p.Document
 }
 
+var _ServiceOptions_ExternalDocs_DEFAULT *ExternalDocs
+
+func (p *_ServiceOptions) GetExternalDocs() (v *ExternalDocs) {
+	if !p.IsSetExternalDocs() {
+		return _ServiceOptions_ExternalDocs_DEFAULT
+	}
+	return p.ExternalDocs
+}
+
 var fieldIDToName__ServiceOptions = map[int16]string{
 	1: "document",
+	2: "external_docs",
 }
 
 func (p *_ServiceOptions) IsSetDocument() bool {
 	return p.Document != nil
 }
 
+func (p *_ServiceOptions) IsSetExternalDocs() bool {
+	return p.ExternalDocs != nil
+}
+
 func (p *_ServiceOptions) Read(iprot thrift.TProtocol) (err error) {
 
 	var fieldTypeId thrift.TType
 	var fieldId int16
 	var issetDocument bool = false
+	var issetExternalDocs bool = false
 
 	if _, err = iprot.ReadStructBegin(); err != nil {
 		goto ReadStructBeginError
@@ -80,6 +96,15 @@ func (p *_ServiceOptions) Read(iprot thrift.TProtocol) (err error) {
 			} else if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
 			}
+		case 2:
+			if fieldTypeId == thrift.STRUCT {
+				if err = p.ReadField2(iprot); err != nil {
+					goto ReadFieldError
+				}
+				issetExternalDocs = true
+			} else if err = iprot.Skip(fieldTypeId); err != nil {
+				goto SkipFieldError
+			}
 		default:
 			if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
@@ -97,6 +122,11 @@ func (p *_ServiceOptions) Read(iprot thrift.TProtocol) (err error) {
 		fieldId = 1
 		goto RequiredFieldNotSetError
 	}
+
+	if !issetExternalDocs {
+		fieldId = 2
+		goto RequiredFieldNotSetError
+	}
 	return nil
 ReadStructBeginError:
 	return thrift.PrependError(fmt.Sprintf("%T read struct begin error: ", p), err)
@@ -123,6 +153,14 @@ func (p *_ServiceOptions) ReadField1(iprot thrift.TProtocol) error {
 	p.Document = _field
 	return nil
 }
+func (p *_ServiceOptions) ReadField2(iprot thrift.TProtocol) error {
+	_field := NewExternalDocs()
+	if err := _field.Read(iprot); err != nil {
+		return err
+	}
+	p.ExternalDocs = _field
+	return nil
+}
 
 func (p *_ServiceOptions) Write(oprot thrift.TProtocol) (err error) {
 	var fieldId int16
@@ -134,6 +172,10 @@ func (p *_ServiceOptions) Write(oprot thrift.TProtocol) (err error) {
 			fieldId = 1
 			goto WriteFieldError
 		}
+		if err = p.writeField2(oprot); err != nil {
+			fieldId = 2
+			goto WriteFieldError
+		}
 	}
 	if err = oprot.WriteFieldStop(); err != nil {
 		goto WriteFieldStopError
@@ -169,6 +211,23 @@ WriteFieldEndError:
 	return thrift.PrependError(fmt.Sprintf("%T write field 1 end error: ", p), err)
 }
 
+func (p *_ServiceOptions) writeField2(oprot thrift.TProtocol) (err error) {
+	if err = oprot.WriteFieldBegin("external_docs", thrift.STRUCT, 2); err != nil {
+		goto WriteFieldBeginError
+	}
+	if err := p.ExternalDocs.Write(oprot); err != nil {
+		return err
+	}
+	if err = oprot.WriteFieldEnd(); err != nil {
+		goto WriteFieldEndError
+	}
+	return nil
+WriteFieldBeginError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 2 begin error: ", p), err)
+WriteFieldEndError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 2 end error: ", p), err)
+}
+
 func (p *_ServiceOptions) String() string {
 	if p == nil {
 		return "<nil>"
@@ -332,7 +391,8 @@ func (p *_StructOptions) String() string {
 }
 
 type _MethodOptions struct {
-	Operation *Operation `thrift:"operation,1,required" json:"operation"`
+	Operation    *Operation    `thrift:"operation,1,required" json:"operation"`
+	ExternalDocs *ExternalDocs `thrift:"external_docs,2,required" json:"external_docs"`
 }
 
 func New_MethodOptions() *_MethodOptions {
@@ -351,19 +411,34 @@ func (p *_MethodOptions) GetOperation() (v *Operation) {
 	return p.Operation
 }
 
+var _MethodOptions_ExternalDocs_DEFAULT *ExternalDocs
+
+func (p *_MethodOptions) GetExternalDocs() (v *ExternalDocs) {
+	if !p.IsSetExternalDocs() {
+		return _MethodOptions_ExternalDocs_DEFAULT
+	}
+	return p.ExternalDocs
+}
+
 var fieldIDToName__MethodOptions = map[int16]string{
 	1: "operation",
+	2: "external_docs",
 }
 
 func (p *_MethodOptions) IsSetOperation() bool {
 	return p.Operation != nil
 }
 
+func (p *_MethodOptions) IsSetExternalDocs() bool {
+	return p.ExternalDocs != nil
+}
+
 func (p *_MethodOptions) Read(iprot thrift.TProtocol) (err error) {
 
 	var fieldTypeId thrift.TType
 	var fieldId int16
 	var issetOperation bool = false
+	var issetExternalDocs bool = false
 
 	if _, err = iprot.ReadStructBegin(); err != nil {
 		goto ReadStructBeginError
@@ -388,6 +463,15 @@ func (p *_MethodOptions) Read(iprot thrift.TProtocol) (err error) {
 			} else if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
 			}
+		case 2:
+			if fieldTypeId == thrift.STRUCT {
+				if err = p.ReadField2(iprot); err != nil {
+					goto ReadFieldError
+				}
+				issetExternalDocs = true
+			} else if err = iprot.Skip(fieldTypeId); err != nil {
+				goto SkipFieldError
+			}
 		default:
 			if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
@@ -405,6 +489,11 @@ func (p *_MethodOptions) Read(iprot thrift.TProtocol) (err error) {
 		fieldId = 1
 		goto RequiredFieldNotSetError
 	}
+
+	if !issetExternalDocs {
+		fieldId = 2
+		goto RequiredFieldNotSetError
+	}
 	return nil
 ReadStructBeginError:
 	return thrift.PrependError(fmt.Sprintf("%T read struct begin error: ", p), err)
@@ -431,6 +520,14 @@ func (p *_MethodOptions) ReadField1(iprot thrift.TProtocol) error {
 	p.Operation = _field
 	return nil
 }
+func (p *_MethodOptions) ReadField2(iprot thrift.TProtocol) error {
+	_field := NewExternalDocs()
+	if err := _field.Read(iprot); err != nil {
+		return err
+	}
+	p.ExternalDocs = _field
+	return nil
+}
 
 func (p *_MethodOptions) Write(oprot thrift.TProtocol) (err error) {
 	var fieldId int16
@@ -442,6 +539,10 @@ func (p *_MethodOptions) Write(oprot thrift.TProtocol) (err error) {
 			fieldId = 1
 			goto WriteFieldError
 		}
+		if err = p.writeField2(oprot); err != nil {
+			fieldId = 2
+			goto WriteFieldError
+		}
 	}
 	if err = oprot.WriteFieldStop(); err != nil {
 		goto WriteFieldStopError
@@ -477,6 +578,23 @@ WriteFieldEndError:
 	return thrift.PrependError(fmt.Sprintf("%T write field 1 end error: ", p), err)
 }
 
+func (p *_MethodOptions) writeField2(oprot thrift.TProtocol) (err error) {
+	if err = oprot.WriteFieldBegin("external_docs", thrift.STRUCT, 2); err != nil {
+		goto WriteFieldBeginError
+	}
+	if err := p.ExternalDocs.Write(oprot); err != nil {
+		return err
+	}
+	if err = oprot.WriteFieldEnd(); err != nil {
+		goto WriteFieldEndError
+	}
+	return nil
+WriteFieldBeginError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 2 begin error: ", p), err)
+WriteFieldEndError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 2 end error: ", p), err)
+}
+
 func (p *_MethodOptions) String() string {
 	if p == nil {
 		return "<nil>"
@@ -3476,6 +3594,7 @@ type Document struct {
 	Tags                   []*Tag                 `thrift:"tags,7" json:"tags"`
 	ExternalDocs           *ExternalDocs          `thrift:"external_docs,8" json:"external_docs"`
 	SpecificationExtension []*NamedAny            `thrift:"specification_extension,9" json:"specification_extension"`
+	Webhooks               *Paths                 `thrift:"webhooks,10" json:"webhooks"`
 }
 
 func NewDocument() *Document {
@@ -3541,16 +3660,26 @@ func (p *Document) GetSpecificationExtension() (v []*NamedAny) {
 	return p.SpecificationExtension
 }
 
+var Document_Webhooks_DEFAULT *Paths
+
+func (p *Document) GetWebhooks() (v *Paths) {
+	if !p.IsSetWebhooks() {
+		return Document_Webhooks_DEFAULT
+	}
+	return p.Webhooks
+}
+
 var fieldIDToName_Document = map[int16]string{
-	1: "openapi",
-	2: "info",
-	3: "servers",
-	4: "paths",
-	5: "components",
-	6: "security",
-	7: "tags",
-	8: "external_docs",
-	9: "specification_extension",
+	1:  "openapi",
+	2:  "info",
+	3:  "servers",
+	4:  "paths",
+	5:  "components",
+	6:  "security",
+	7:  "tags",
+	8:  "external_docs",
+	9:  "specification_extension",
+	10: "webhooks",
 }
 
 func (p *Document) IsSetInfo() bool {
@@ -3569,6 +3698,10 @@ func (p *Document) IsSetExternalDocs() bool {
 	return p.ExternalDocs != nil
 }
 
+func (p *Document) IsSetWebhooks() bool {
+	return p.Webhooks != nil
+}
+
 func (p *Document) Read(iprot thrift.TProtocol) (err error) {
 
 	var fieldTypeId thrift.TType
@@ -3660,6 +3793,14 @@ func (p *Document) Read(iprot thrift.TProtocol) (err error) {
 			} else if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
 			}
+		case 10:
+			if fieldTypeId == thrift.STRUCT {
+				if err = p.ReadField10(iprot); err != nil {
+					goto ReadFieldError
+				}
+			} else if err = iprot.Skip(fieldTypeId); err != nil {
+				goto SkipFieldError
+			}
 		default:
 			if err = iprot.Skip(fieldTypeId); err != nil {
 				goto SkipFieldError
@@ -3824,6 +3965,14 @@ func (p *Document) ReadField9(iprot thrift.TProtocol) error {
 	p.SpecificationExtension = _field
 	return nil
 }
+func (p *Document) ReadField10(iprot thrift.TProtocol) error {
+	_field := NewPaths()
+	if err := _field.Read(iprot); err != nil {
+		return err
+	}
+	p.Webhooks = _field
+	return nil
+}
 
 func (p *Document) Write(oprot thrift.TProtocol) (err error) {
 	var fieldId int16
@@ -3867,6 +4016,10 @@ func (p *Document) Write(oprot thrift.TProtocol) (err error) {
 			fieldId = 9
 			goto WriteFieldError
 		}
+		if err = p.writeField10(oprot); err != nil {
+			fieldId = 10
+			goto WriteFieldError
+		}
 	}
 	if err = oprot.WriteFieldStop(); err != nil {
 		goto WriteFieldStopError
@@ -4070,6 +4223,23 @@ WriteFieldEndError:
 	return thrift.PrependError(fmt.Sprintf("%T write field 9 end error: ", p), err)
 }
 
+func (p *Document) writeField10(oprot thrift.TProtocol) (err error) {
+	if err = oprot.WriteFieldBegin("webhooks", thrift.STRUCT, 10); err != nil {
+		goto WriteFieldBeginError
+	}
+	if err := p.Webhooks.Write(oprot); err != nil {
+		return err
+	}
+	if err = oprot.WriteFieldEnd(); err != nil {
+		goto WriteFieldEndError
+	}
+	return nil
+WriteFieldBeginError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 10 begin error: ", p), err)
+WriteFieldEndError:
+	return thrift.PrependError(fmt.Sprintf("%T write field 10 end error: ", p), err)
+}
+
 func (p *Document) String() string {
 	if p == nil {
 		return "<nil>"
@@ -18083,18 +18253,18 @@ type Schema struct {
 	Deprecated             bool                      `thrift:"deprecated,8" json:"deprecated"`
 	Title                  string                    `thrift:"title,9" json:"title"`
 	MultipleOf             float64                   `thrift:"multiple_of,10" json:"multiple_of"`
-	Maximum                float64                   `thrift:"maximum,11" json:"maximum"`
+	Maximum                *float64                  `thrift:"maximum,11,optional" json:"maximum,omitempty"`
 	ExclusiveMaximum       bool                      `thrift:"exclusive_maximum,12" json:"exclusive_maximum"`
-	Minimum                float64                   `thrift:"minimum,13" json:"minimum"`
+	Minimum                *float64                  `thrift:"minimum,13,optional" json:"minimum,omitempty"`
 	ExclusiveMinimum       bool                      `thrift:"exclusive_minimum,14" json:"exclusive_minimum"`
-	MaxLength              int64                     `thrift:"max_length,15" json:"max_length"`
-	MinLength              int64                     `thrift:"min_length,16" json:"min_length"`
+	MaxLength              *int64                    `thrift:"max_length,15,optional" json:"max_length,omitempty"`
+	MinLength              *int64                    `thrift:"min_length,16,optional" json:"min_length,omitempty"`
 	Pattern                string                    `thrift:"pattern,17" json:"pattern"`
-	MaxItems               int64                     `thrift:"max_items,18" json:"max_items"`
-	MinItems               int64                     `thrift:"min_items,19" json:"min_items"`
+	MaxItems               *int64                    `thrift:"max_items,18,optional" json:"max_items,omitempty"`
+	MinItems               *int64                    `thrift:"min_items,19,optional" json:"min_items,omitempty"`
 	UniqueItems            bool                      `thrift:"unique_items,20" json:"unique_items"`
-	MaxProperties          int64                     `thrift:"max_properties,21" json:"max_properties"`
-	MinProperties          int64                     `thrift:"min_properties,22" json:"min_properties"`
+	MaxProperties          *int64                    `thrift:"max_properties,21,optional" json:"max_properties,omitempty"`
+	MinProperties          *int64                    `thrift:"min_properties,22,optional" json:"min_properties,omitempty"`
 	Required               []string                  `thrift:"required,23" json:"required"`
 	Enum                   []*Any                    `thrift:"enum,24" json:"enum"`
 	Type                   string                    `thrift:"type,25" json:"type"`
@@ -18178,52 +18348,92 @@ func (p *Schema) GetMultipleOf() (v float64) {
 	return p.MultipleOf
 }
 
+var Schema_Maximum_DEFAULT float64
+
 func (p *Schema) GetMaximum() (v float64) {
-	return p.Maximum
+	if !p.IsSetMaximum() {
+		return Schema_Maximum_DEFAULT
+	}
+	return *p.Maximum
 }
 
 func (p *Schema) GetExclusiveMaximum() (v bool) {
 	return p.ExclusiveMaximum
 }
 
+var Schema_Minimum_DEFAULT float64
+
 func (p *Schema) GetMinimum() (v float64) {
-	return p.Minimum
+	if !p.IsSetMinimum() {
+		return Schema_Minimum_DEFAULT
+	}
+	return *p.Minimum
 }
 
 func (p *Schema) GetExclusiveMinimum() (v bool) {
 	return p.ExclusiveMinimum
 }
 
+var Schema_MaxLength_DEFAULT int64
+
 func (p *Schema) GetMaxLength() (v int64) {
-	return p.MaxLength
+	if !p.IsSetMaxLength() {
+		return Schema_MaxLength_DEFAULT
+	}
+	return *p.MaxLength
 }
 
+var Schema_MinLength_DEFAULT int64
+
 func (p *Schema) GetMinLength() (v int64) {
-	return p.MinLength
+	if !p.IsSetMinLength() {
+		return Schema_MinLength_DEFAULT
+	}
+	return *p.MinLength
 }
 
 func (p *Schema) GetPattern() (v string) {
 	return p.Pattern
 }
 
+var Schema_MaxItems_DEFAULT int64
+
 func (p *Schema) GetMaxItems() (v int64) {
-	return p.MaxItems
+	if !p.IsSetMaxItems() {
+		return Schema_MaxItems_DEFAULT
+	}
+	return *p.MaxItems
 }
 
+var Schema_MinItems_DEFAULT int64
+
 func (p *Schema) GetMinItems() (v int64) {
-	return p.MinItems
+	if !p.IsSetMinItems() {
+		return Schema_MinItems_DEFAULT
+	}
+	return *p.MinItems
 }
 
 func (p *Schema) GetUniqueItems() (v bool) {
 	return p.UniqueItems
 }
 
+var Schema_MaxProperties_DEFAULT int64
+
 func (p *Schema) GetMaxProperties() (v int64) {
-	return p.MaxProperties
+	if !p.IsSetMaxProperties() {
+		return Schema_MaxProperties_DEFAULT
+	}
+	return *p.MaxProperties
 }
 
+var Schema_MinProperties_DEFAULT int64
+
 func (p *Schema) GetMinProperties() (v int64) {
-	return p.MinProperties
+	if !p.IsSetMinProperties() {
+		return Schema_MinProperties_DEFAULT
+	}
+	return *p.MinProperties
 }
 
 func (p *Schema) GetRequired() (v []string) {
@@ -18362,6 +18572,38 @@ func (p *Schema) IsSetExample() bool {
 	return p.Example != nil
 }
 
+func (p *Schema) IsSetMaximum() bool {
+	return p.Maximum != nil
+}
+
+func (p *Schema) IsSetMinimum() bool {
+	return p.Minimum != nil
+}
+
+func (p *Schema) IsSetMaxLength() bool {
+	return p.MaxLength != nil
+}
+
+func (p *Schema) IsSetMinLength() bool {
+	return p.MinLength != nil
+}
+
+func (p *Schema) IsSetMaxItems() bool {
+	return p.MaxItems != nil
+}
+
+func (p *Schema) IsSetMinItems() bool {
+	return p.MinItems != nil
+}
+
+func (p *Schema) IsSetMaxProperties() bool {
+	return p.MaxProperties != nil
+}
+
+func (p *Schema) IsSetMinProperties() bool {
+	return p.MinProperties != nil
+}
+
 func (p *Schema) IsSetNot() bool {
 	return p.Not != nil
 }
@@ -18818,11 +19060,11 @@ func (p *Schema) ReadField10(iprot thrift.TProtocol) error {
 }
 func (p *Schema) ReadField11(iprot thrift.TProtocol) error {
 
-	var _field float64
+	var _field *float64
 	if v, err := iprot.ReadDouble(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.Maximum = _field
 	return nil
@@ -18840,11 +19082,11 @@ func (p *Schema) ReadField12(iprot thrift.TProtocol) error {
 }
 func (p *Schema) ReadField13(iprot thrift.TProtocol) error {
 
-	var _field float64
+	var _field *float64
 	if v, err := iprot.ReadDouble(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.Minimum = _field
 	return nil
@@ -18862,22 +19104,22 @@ func (p *Schema) ReadField14(iprot thrift.TProtocol) error {
 }
 func (p *Schema) ReadField15(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MaxLength = _field
 	return nil
 }
 func (p *Schema) ReadField16(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MinLength = _field
 	return nil
@@ -18895,22 +19137,22 @@ func (p *Schema) ReadField17(iprot thrift.TProtocol) error {
 }
 func (p *Schema) ReadField18(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MaxItems = _field
 	return nil
 }
 func (p *Schema) ReadField19(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MinItems = _field
 	return nil
@@ -18928,22 +19170,22 @@ func (p *Schema) ReadField20(iprot thrift.TProtocol) error {
 }
 func (p *Schema) ReadField21(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MaxProperties = _field
 	return nil
 }
 func (p *Schema) ReadField22(iprot thrift.TProtocol) error {
 
-	var _field int64
+	var _field *int64
 	if v, err := iprot.ReadI64(); err != nil {
 		return err
 	} else {
-		_field = v
+		_field = &v
 	}
 	p.MinProperties = _field
 	return nil
@@ -19499,14 +19741,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField11(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("maximum", thrift.DOUBLE, 11); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteDouble(p.Maximum); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMaximum() {
+		if err = oprot.WriteFieldBegin("maximum", thrift.DOUBLE, 11); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteDouble(*p.Maximum); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19533,14 +19777,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField13(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("minimum", thrift.DOUBLE, 13); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteDouble(p.Minimum); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMinimum() {
+		if err = oprot.WriteFieldBegin("minimum", thrift.DOUBLE, 13); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteDouble(*p.Minimum); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19567,14 +19813,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField15(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("max_length", thrift.I64, 15); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MaxLength); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMaxLength() {
+		if err = oprot.WriteFieldBegin("max_length", thrift.I64, 15); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MaxLength); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19584,14 +19832,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField16(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("min_length", thrift.I64, 16); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MinLength); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMinLength() {
+		if err = oprot.WriteFieldBegin("min_length", thrift.I64, 16); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MinLength); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19618,14 +19868,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField18(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("max_items", thrift.I64, 18); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MaxItems); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMaxItems() {
+		if err = oprot.WriteFieldBegin("max_items", thrift.I64, 18); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MaxItems); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19635,14 +19887,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField19(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("min_items", thrift.I64, 19); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MinItems); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMinItems() {
+		if err = oprot.WriteFieldBegin("min_items", thrift.I64, 19); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MinItems); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19669,14 +19923,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField21(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("max_properties", thrift.I64, 21); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MaxProperties); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMaxProperties() {
+		if err = oprot.WriteFieldBegin("max_properties", thrift.I64, 21); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MaxProperties); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -19686,14 +19942,16 @@ WriteFieldEndError:
 }
 
 func (p *Schema) writeField22(oprot thrift.TProtocol) (err error) {
-	if err = oprot.WriteFieldBegin("min_properties", thrift.I64, 22); err != nil {
-		goto WriteFieldBeginError
-	}
-	if err := oprot.WriteI64(p.MinProperties); err != nil {
-		return err
-	}
-	if err = oprot.WriteFieldEnd(); err != nil {
-		goto WriteFieldEndError
+	if p.IsSetMinProperties() {
+		if err = oprot.WriteFieldBegin("min_properties", thrift.I64, 22); err != nil {
+			goto WriteFieldBeginError
+		}
+		if err := oprot.WriteI64(*p.MinProperties); err != nil {
+			return err
+		}
+		if err = oprot.WriteFieldEnd(); err != nil {
+			goto WriteFieldEndError
+		}
 	}
 	return nil
 WriteFieldBeginError:
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import "encoding/json"
+
+// UnmarshalJSON fills the unexported type and in fields of SecurityScheme,
+// accepting both the thrift field names (_type, _in) and the OpenAPI ones (type, in).
+func (p *SecurityScheme) UnmarshalJSON(data []byte) error {
+	type securityScheme SecurityScheme
+	aux := struct {
+		*securityScheme
+		Type       string `json:"type"`
+		ThriftType string `json:"_type"`
+		In         string `json:"in"`
+		ThriftIn   string `json:"_in"`
+	}{securityScheme: (*securityScheme)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p._Type = aux.Type
+	if aux.ThriftType != "" {
+		p._Type = aux.ThriftType
+	}
+	p._In = aux.In
+	if aux.ThriftIn != "" {
+		p._In = aux.ThriftIn
+	}
+	return nil
+}
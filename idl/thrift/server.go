@@ -0,0 +1,38 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import "encoding/json"
+
+// UnmarshalJSON fills the unexported default field of ServerVariable,
+// accepting both the thrift field name (_default) and the OpenAPI one (default).
+func (p *ServerVariable) UnmarshalJSON(data []byte) error {
+	type serverVariable ServerVariable
+	aux := struct {
+		*serverVariable
+		Default       string `json:"default"`
+		ThriftDefault string `json:"_default"`
+	}{serverVariable: (*serverVariable)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p._Default = aux.Default
+	if aux.ThriftDefault != "" {
+		p._Default = aux.ThriftDefault
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"strings"
+
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"gopkg.in/yaml.v3"
+)
+
+// SplitYAMLValue produces a serialized YAML representation of the document like YAMLValue,
+// but moves every component schema into its own file under schemaDir.
+// References to the schemas are replaced with relative file references.
+// It returns the main document and the content of the schema files keyed by schema name.
+func (m *Document) SplitYAMLValue(comment, schemaDir string) ([]byte, map[string][]byte, error) {
+	schemas := map[string][]byte{}
+	if m.Components == nil || m.Components.Schemas == nil {
+		bytes, err := m.YAMLValue(comment)
+		return bytes, schemas, err
+	}
+
+	for _, pair := range m.Components.Schemas.AdditionalProperties {
+		rawInfo := pair.Value.ToRawInfo()
+		rewriteSchemaRefs(rawInfo, "./")
+		bytes, err := yaml.Marshal(&yaml.Node{
+			Kind:        yaml.DocumentNode,
+			Content:     []*yaml.Node{rawInfo},
+			HeadComment: comment,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		schemas[pair.Name] = bytes
+	}
+
+	componentSchemas := m.Components.Schemas
+	m.Components.Schemas = nil
+	rawInfo := m.ToRawInfo()
+	m.Components.Schemas = componentSchemas
+
+	// Drop the components if the schemas were all it had
+	for i := 0; i+1 < len(rawInfo.Content); i += 2 {
+		if rawInfo.Content[i].Value == "components" && len(rawInfo.Content[i+1].Content) == 0 {
+			rawInfo.Content = append(rawInfo.Content[:i], rawInfo.Content[i+2:]...)
+			break
+		}
+	}
+	rewriteSchemaRefs(rawInfo, "./"+schemaDir+"/")
+	bytes, err := yaml.Marshal(&yaml.Node{
+		Kind:        yaml.DocumentNode,
+		Content:     []*yaml.Node{rawInfo},
+		HeadComment: comment,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes, schemas, nil
+}
+
+// rewriteSchemaRefs replaces the component schema references in the node with references
+// to the schema files under prefix.
+func rewriteSchemaRefs(node *yaml.Node, prefix string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && strings.HasPrefix(value.Value, consts.ComponentSchemaPrefix) {
+				value.Value = prefix + strings.TrimPrefix(value.Value, consts.ComponentSchemaPrefix) + ".yaml"
+			}
+		}
+	}
+	for _, child := range node.Content {
+		rewriteSchemaRefs(child, prefix)
+	}
+}
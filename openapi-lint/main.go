@@ -0,0 +1,91 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// openapi-lint validates the OpenAPI documents and checks them for common issues, e.g. in CI:
+//
+//	openapi-lint -fail_on=warning swagger/openapi.yaml
+//
+// It exits with status 1 when a document can't be loaded or has a violation of the fail_on severity.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hertz-contrib/swagger-generate/common/linter"
+)
+
+func main() {
+	failOn := flag.String("fail_on", string(linter.SeverityError), `least severity of the violations failing the run, one of "error", "warning" and "info"`)
+	format := flag.String("format", "text", `output format of the violations, "text" or "json"`)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: openapi-lint [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	failSeverity, err := linter.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format '%s', it must be text or json\n", *format)
+		os.Exit(2)
+	}
+
+	failed := false
+	results := make(map[string][]linter.Violation)
+	for _, file := range flag.Args() {
+		violations, err := linter.LintFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			failed = true
+			continue
+		}
+		results[file] = violations
+		for _, v := range violations {
+			if v.Severity.AtLeast(failSeverity) {
+				failed = true
+			}
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode the violations: %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, file := range flag.Args() {
+			for _, v := range results[file] {
+				fmt.Printf("%s: %s\n", file, v)
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
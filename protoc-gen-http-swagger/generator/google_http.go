@@ -0,0 +1,324 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"github.com/hertz-contrib/swagger-generate/idl/protobuf/openapi"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldPathPattern matches the field path of a path template variable, e.g. book.name.
+var fieldPathPattern = regexp.MustCompile(`^[A-Za-z_][\w]*(\.[A-Za-z_][\w]*)*$`)
+
+// pathVariable is a variable of a google.api.http path template, e.g. {name=shelves/*/books/*}.
+type pathVariable struct {
+	// fieldPath is the path of the field bound to the variable, e.g. name
+	fieldPath string
+	// segments are the segments matched by the variable, e.g. shelves/*/books/*, empty for a
+	// single segment
+	segments string
+}
+
+// parsePathTemplate parses the google.api.http path template into an OpenAPI path and its
+// variables. A variable matching several segments, e.g. {name=shelves/*/books/*} or {path=**},
+// is flattened to a single {name} parameter, the verb of a custom method, e.g. :cancel, is kept.
+func parsePathTemplate(template string) (string, []pathVariable, error) {
+	var path strings.Builder
+	var variables []pathVariable
+	for i := 0; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", nil, fmt.Errorf("unclosed variable at %d", i)
+			}
+			variable := template[i+1 : i+end]
+			if strings.ContainsRune(variable, '{') {
+				return "", nil, fmt.Errorf("nested variable at %d", i)
+			}
+			fieldPath, segments := variable, ""
+			if eq := strings.IndexByte(variable, '='); eq >= 0 {
+				fieldPath, segments = variable[:eq], variable[eq+1:]
+				if segments == "" {
+					return "", nil, fmt.Errorf("empty segments of variable %s", fieldPath)
+				}
+			}
+			if !fieldPathPattern.MatchString(fieldPath) {
+				return "", nil, fmt.Errorf("invalid field path %q", fieldPath)
+			}
+			variables = append(variables, pathVariable{fieldPath: fieldPath, segments: segments})
+			path.WriteString("{" + fieldPath + "}")
+			i += end
+		case '}':
+			return "", nil, fmt.Errorf("unexpected '}' at %d", i)
+		default:
+			path.WriteByte(template[i])
+		}
+	}
+	return path.String(), variables, nil
+}
+
+// segmentsPattern returns the regular expression of the value of a variable matching the segments,
+// e.g. ^shelves/[^/]+/books/[^/]+$ for shelves/*/books/*, or "" when it matches any value.
+func segmentsPattern(segments string) string {
+	if segments == "" || segments == "*" || segments == "**" {
+		return ""
+	}
+	parts := strings.Split(segments, "/")
+	for i, part := range parts {
+		switch part {
+		case "*":
+			parts[i] = "[^/]+"
+		case "**":
+			parts[i] = ".+"
+		default:
+			parts[i] = regexp.QuoteMeta(part)
+		}
+	}
+	return "^" + strings.Join(parts, "/") + "$"
+}
+
+// httpRule is an HTTP binding of a method declared by the google.api.http annotation.
+type httpRule struct {
+	method       string
+	path         string
+	body         string
+	responseBody string
+}
+
+// getHttpRules returns the HTTP bindings of the method declared by the google.api.http annotation,
+// followed by its additional bindings.
+func getHttpRules(method *protogen.Method) []httpRule {
+	rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil
+	}
+	var rules []httpRule
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		var methodName, path string
+		switch pattern := r.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			methodName, path = consts.HttpMethodGet, pattern.Get
+		case *annotations.HttpRule_Put:
+			methodName, path = consts.HttpMethodPut, pattern.Put
+		case *annotations.HttpRule_Post:
+			methodName, path = consts.HttpMethodPost, pattern.Post
+		case *annotations.HttpRule_Delete:
+			methodName, path = consts.HttpMethodDelete, pattern.Delete
+		case *annotations.HttpRule_Patch:
+			methodName, path = consts.HttpMethodPatch, pattern.Patch
+		case *annotations.HttpRule_Custom:
+			methodName, path = strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+			if methodName != consts.HttpMethodOptions && methodName != consts.HttpMethodHead {
+				logs.Warnf("custom method '%s' of '%s' is not supported, only OPTIONS and HEAD can be documented", methodName, method.Desc.FullName())
+				continue
+			}
+		default:
+			continue
+		}
+		rules = append(rules, httpRule{
+			method:       methodName,
+			path:         path,
+			body:         r.GetBody(),
+			responseBody: r.GetResponseBody(),
+		})
+	}
+	return rules
+}
+
+// buildGoogleOperation builds the operation of a google.api.http binding like grpc-gateway maps it:
+// the path variables are bound to the fields they name, the body to the field named by `body` or
+// to the whole message for "*", and the other fields to query parameters. It fails if the path
+// template can't be parsed.
+func (g *OpenAPIGenerator) buildGoogleOperation(
+	d *openapi.Document,
+	rule httpRule,
+	operationID string,
+	tagName string,
+	description string,
+	defaultHost string,
+	inputMessage *protogen.Message,
+	outputMessage *protogen.Message,
+) (*openapi.Operation, string, error) {
+	var parameters []*openapi.ParameterOrReference
+	// Top level fields bound to the path or the body, which are not query parameters
+	bound := map[string]bool{}
+
+	path, variables, err := parsePathTemplate(rule.path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse path template '%s': %s", rule.path, err.Error())
+	}
+	for _, variable := range variables {
+		name := variable.fieldPath
+		bound[strings.SplitN(name, ".", 2)[0]] = true
+		var parameter *openapi.Parameter
+		if field := findField(inputMessage, name); field != nil && variable.segments == "" {
+			parameter = g.buildFieldParameter(field, name, consts.ParameterInPath)
+		} else {
+			if field == nil {
+				logs.Warnf("path variable '%s' of '%s' is not a field of message '%s'", name, rule.path, inputMessage.Desc.FullName())
+			}
+			// A variable matching several segments is a string holding them, slashes included
+			parameter = &openapi.Parameter{
+				Name:     name,
+				In:       consts.ParameterInPath,
+				Required: true,
+				Schema: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: &openapi.Schema{
+					Type:    "string",
+					Pattern: segmentsPattern(variable.segments),
+				}}},
+			}
+			if field != nil {
+				parameter.Description = g.filterCommentString(field.Comments.Leading)
+			}
+		}
+		parameters = append(parameters, &openapi.ParameterOrReference{
+			Oneof: &openapi.ParameterOrReference_Parameter{Parameter: parameter},
+		})
+	}
+
+	var requestSchema *openapi.SchemaOrReference
+	switch rule.body {
+	case "":
+	case "*":
+		requestSchema = g.reflect.schemaOrReferenceForMessage(inputMessage.Desc)
+	default:
+		bound[rule.body] = true
+		if field := findField(inputMessage, rule.body); field != nil {
+			requestSchema = g.reflect.schemaOrReferenceForField(field.Desc)
+		} else {
+			logs.Warnf("body '%s' of '%s' is not a field of message '%s'", rule.body, rule.path, inputMessage.Desc.FullName())
+		}
+	}
+
+	if rule.body != "*" {
+		for _, field := range inputMessage.Fields {
+			if bound[string(field.Desc.Name())] || !isQueryParameterField(field.Desc) {
+				continue
+			}
+			parameters = append(parameters, &openapi.ParameterOrReference{
+				Oneof: &openapi.ParameterOrReference_Parameter{
+					Parameter: g.buildFieldParameter(field, g.reflect.formatFieldName(field.Desc), consts.ParameterInQuery),
+				},
+			})
+		}
+	}
+
+	var requestBody *openapi.RequestBodyOrReference
+	if requestSchema != nil {
+		requestBody = &openapi.RequestBodyOrReference{
+			Oneof: &openapi.RequestBodyOrReference_RequestBody{
+				RequestBody: &openapi.RequestBody{
+					Description: g.filterCommentString(inputMessage.Comments.Leading),
+					Content: &openapi.MediaTypes{
+						AdditionalProperties: []*openapi.NamedMediaType{
+							{Name: consts.ContentTypeJSON, Value: &openapi.MediaType{Schema: requestSchema}},
+						},
+					},
+					Required: true,
+				},
+			},
+		}
+	}
+
+	var responseSchema *openapi.SchemaOrReference
+	if rule.responseBody == "" {
+		responseSchema = g.reflect.schemaOrReferenceForMessage(outputMessage.Desc)
+	} else if field := findField(outputMessage, rule.responseBody); field != nil {
+		responseSchema = g.reflect.schemaOrReferenceForField(field.Desc)
+	} else {
+		logs.Warnf("response body '%s' of '%s' is not a field of message '%s'", rule.responseBody, rule.path, outputMessage.Desc.FullName())
+	}
+	desc := g.filterCommentString(outputMessage.Comments.Leading)
+	if desc == "" {
+		desc = consts.DefaultResponseDesc
+	}
+	response := &openapi.Response{Description: desc}
+	if responseSchema != nil {
+		response.Content = &openapi.MediaTypes{
+			AdditionalProperties: []*openapi.NamedMediaType{
+				{Name: consts.ContentTypeJSON, Value: &openapi.MediaType{Schema: responseSchema}},
+			},
+		}
+	}
+	responses := &openapi.Responses{
+		ResponseOrReference: []*openapi.NamedResponseOrReference{
+			{
+				Name: consts.StatusOK,
+				Value: &openapi.ResponseOrReference{
+					Oneof: &openapi.ResponseOrReference_Response{Response: response},
+				},
+			},
+		},
+	}
+
+	return newOperation(tagName, description, operationID, defaultHost, parameters, requestBody, responses), path, nil
+}
+
+// findField returns the field of the message named by the dot separated path of proto field names,
+// e.g. book.name, or nil if there is none.
+func findField(message *protogen.Message, path string) *protogen.Field {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		var found *protogen.Field
+		for _, field := range message.Fields {
+			if string(field.Desc.Name()) == name {
+				found = field
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		if i == len(names)-1 {
+			return found
+		}
+		if found.Message == nil {
+			return nil
+		}
+		message = found.Message
+	}
+	return nil
+}
+
+// isQueryParameterField reports whether grpc-gateway binds the field from a query parameter, that
+// is a field of a scalar or enum type, a list of them, or a well-known type mapped to a scalar.
+func isQueryParameterField(field protoreflect.FieldDescriptor) bool {
+	if field.IsMap() {
+		return false
+	}
+	if field.Kind() != protoreflect.MessageKind && field.Kind() != protoreflect.GroupKind {
+		return true
+	}
+	switch field.Message().FullName() {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return true
+	}
+	return false
+}
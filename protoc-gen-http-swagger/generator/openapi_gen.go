@@ -37,7 +37,9 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
@@ -50,16 +52,31 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/runtime/protoimpl"
 	any_pb "google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 type Configuration struct {
-	Version        *string
-	Title          *string
-	Description    *string
-	Naming         *string
-	FQSchemaNaming *bool
-	EnumType       *string
-	OutputMode     *string
+	Version             *string
+	Title               *string
+	Description         *string
+	Naming              *string
+	FQSchemaNaming      *bool
+	InlineSchemas       *bool
+	PruneUnused         *bool
+	ClosedSchemas       *bool
+	EnumType            *string
+	OutputMode          *string
+	FileHeader          *string
+	OperationIDTemplate *string
+	OpenAPIVersion      *string
+	BodySchemaSuffix    *string
+	FormSchemaSuffix    *string
+	RawBodySchemaSuffix *string
+	HttpAnnotation      *string
+	Validate            *bool
+	SynthesizeExamples  *bool
+	BasePath            *string
+	Swagger2            *bool
 }
 
 // In order to dynamically add google.rpc.Status responses we need
@@ -76,26 +93,179 @@ type OpenAPIGenerator struct {
 	inputFiles       []*protogen.File
 	reflect          *OpenAPIReflector
 	generatedSchemas []string // Names of schemas that have already been generated.
+	// schemaOrigins names the type each schema name was generated from, to report colliding names
+	schemaOrigins map[string]string
+	// schemaNameErr is the first collision of schema names, which fails the document
+	schemaNameErr error
+	// operationIDTemplate formats the operation IDs from the service and method names
+	operationIDTemplate *template.Template
 }
 
-// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation.
-func NewOpenAPIGenerator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) *OpenAPIGenerator {
+// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation. It fails if the
+// operation ID template can't be parsed.
+func NewOpenAPIGenerator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) (*OpenAPIGenerator, error) {
+	operationIDTemplate, err := common.NewOperationIDTemplate(*conf.OperationIDTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operation id template: %s", err.Error())
+	}
 	return &OpenAPIGenerator{
-		conf:             conf,
-		plugin:           plugin,
-		inputFiles:       inputFiles,
-		reflect:          NewOpenAPIReflector(conf),
-		generatedSchemas: make([]string, 0),
+		operationIDTemplate: operationIDTemplate,
+		conf:                conf,
+		plugin:              plugin,
+		inputFiles:          inputFiles,
+		reflect:             NewOpenAPIReflector(conf),
+		generatedSchemas:    make([]string, 0),
+		schemaOrigins:       make(map[string]string),
+	}, nil
+}
+
+// GenerateFromRequest builds the OpenAPI document of the files to generate in the code generator
+// request without running the plugin, so that tools and tests can call the generator from Go code.
+// The unset options of conf take the defaults of the plugin options. The document is not written
+// anywhere; use its YAMLValue method to get the bytes of openapi.yaml, and ConvertToOpenAPI31 or
+// ConvertToSwagger2 of the common utils for the OpenAPI version 3.1.0 or Swagger 2.0.
+func GenerateFromRequest(req *pluginpb.CodeGeneratorRequest, conf Configuration) (*openapi.Document, error) {
+	plugin, err := protogen.Options{}.New(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code generator request: %s", err.Error())
+	}
+	conf.setDefaults()
+	g, err := NewOpenAPIGenerator(plugin, conf, plugin.Files)
+	if err != nil {
+		return nil, err
+	}
+	return g.buildDocument()
+}
+
+// setDefaults sets the unset options to the defaults of the plugin options.
+func (c *Configuration) setDefaults() {
+	if c.Version == nil {
+		c.Version = stringPtr("3.0.3")
+	}
+	if c.Title == nil {
+		c.Title = stringPtr("")
+	}
+	if c.Description == nil {
+		c.Description = stringPtr("")
+	}
+	if c.Naming == nil {
+		c.Naming = stringPtr("json")
+	}
+	if c.FQSchemaNaming == nil {
+		c.FQSchemaNaming = boolPtr(false)
+	}
+	if c.InlineSchemas == nil {
+		c.InlineSchemas = boolPtr(false)
+	}
+	if c.PruneUnused == nil {
+		c.PruneUnused = boolPtr(false)
+	}
+	if c.ClosedSchemas == nil {
+		c.ClosedSchemas = boolPtr(false)
+	}
+	if c.EnumType == nil {
+		c.EnumType = stringPtr("integer")
+	}
+	if c.OutputMode == nil {
+		c.OutputMode = stringPtr("merged")
+	}
+	if c.OperationIDTemplate == nil {
+		c.OperationIDTemplate = stringPtr(consts.DefaultOperationIDTemplate)
+	}
+	if c.OpenAPIVersion == nil {
+		c.OpenAPIVersion = stringPtr(consts.OpenAPIVersion)
+	}
+	if c.BodySchemaSuffix == nil {
+		c.BodySchemaSuffix = stringPtr(consts.ComponentSchemaSuffixBody)
+	}
+	if c.FormSchemaSuffix == nil {
+		c.FormSchemaSuffix = stringPtr(consts.ComponentSchemaSuffixForm)
+	}
+	if c.RawBodySchemaSuffix == nil {
+		c.RawBodySchemaSuffix = stringPtr(consts.ComponentSchemaSuffixRawBody)
+	}
+	if c.HttpAnnotation == nil {
+		c.HttpAnnotation = stringPtr(consts.HttpAnnotationHertz)
+	}
+	if c.Validate == nil {
+		c.Validate = boolPtr(false)
+	}
+	if c.SynthesizeExamples == nil {
+		c.SynthesizeExamples = boolPtr(false)
+	}
+	if c.BasePath == nil {
+		c.BasePath = stringPtr("")
+	}
+	if c.Swagger2 == nil {
+		c.Swagger2 = boolPtr(false)
 	}
 }
 
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// comment returns the head comment of the generated YAML files, the banner preceded by the file header.
+func (g *OpenAPIGenerator) comment() (string, error) {
+	banner := "Generated with " + consts.PluginNameProtocHttpSwagger + "\n" + consts.InfoURL + consts.PluginNameProtocHttpSwagger
+	var header string
+	if g.conf.FileHeader != nil {
+		var err error
+		if header, err = common.FileHeader(*g.conf.FileHeader); err != nil {
+			return "", err
+		}
+	}
+	if header == "" {
+		return banner, nil
+	}
+	return header + "\n\n" + banner, nil
+}
+
 // Run runs the generator.
 func (g *OpenAPIGenerator) Run(outputFile *protogen.GeneratedFile) error {
-	d := g.buildDocument()
-	bytes, err := d.YAMLValue("Generated with " + consts.PluginNameProtocHttpSwagger + "\n" + consts.InfoURL + consts.PluginNameProtocHttpSwagger)
+	d, err := g.buildDocument()
+	if err != nil {
+		return err
+	}
+	comment, err := g.comment()
+	if err != nil {
+		return fmt.Errorf("failed to read file header: %s", err.Error())
+	}
+	bytes, err := d.YAMLValue(comment)
 	if err != nil {
 		return fmt.Errorf("failed to marshal yaml: %s", err.Error())
 	}
+	if *g.conf.PruneUnused {
+		if bytes, err = common.PruneSchemas(bytes); err != nil {
+			return fmt.Errorf("failed to prune schemas: %s", err.Error())
+		}
+	}
+	if *g.conf.InlineSchemas {
+		if bytes, err = common.InlineSchemas(bytes); err != nil {
+			return fmt.Errorf("failed to inline schemas: %s", err.Error())
+		}
+	}
+	if *g.conf.Validate {
+		if err = common.ValidateOpenAPI(bytes); err != nil {
+			return fmt.Errorf("failed to validate openapi document: %s", err.Error())
+		}
+	}
+	// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+	if *g.conf.OpenAPIVersion == consts.OpenAPIVersion31 {
+		if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+			return fmt.Errorf("failed to convert to openapi %s: %s", consts.OpenAPIVersion31, err.Error())
+		}
+	}
+	// Likewise the document is converted to Swagger 2.0 after it is validated.
+	if *g.conf.Swagger2 {
+		if bytes, err = common.ConvertToSwagger2(bytes); err != nil {
+			return fmt.Errorf("failed to convert to swagger 2.0: %s", err.Error())
+		}
+	}
 	if _, err = outputFile.Write(bytes); err != nil {
 		return fmt.Errorf("failed to write yaml: %s", err.Error())
 	}
@@ -103,10 +273,10 @@ func (g *OpenAPIGenerator) Run(outputFile *protogen.GeneratedFile) error {
 }
 
 // buildDocument builds an OpenAPIv3 document for a plugin request.
-func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
+func (g *OpenAPIGenerator) buildDocument() (*openapi.Document, error) {
 	d := &openapi.Document{}
 
-	d.Openapi = consts.OpenAPIVersion
+	d.Openapi = *g.conf.OpenAPIVersion
 	d.Info = &openapi.Info{
 		Version:     *g.conf.Version,
 		Title:       *g.conf.Title,
@@ -123,6 +293,7 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	// Go through the files and add the services to the documents, keeping
 	// track of which schemas are referenced in the response so we can
 	// add them later.
+	var declaredTags []string
 	for _, file := range g.inputFiles {
 		if file.Generate {
 			// Merge any `Document` annotations with the current
@@ -130,11 +301,16 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 			if extDocument != nil {
 				if doc, ok := extDocument.(*openapi.Document); ok {
 					proto.Merge(d, doc)
+					for _, tag := range doc.GetTags() {
+						declaredTags = append(declaredTags, tag.Name)
+					}
 				} else {
 					logs.Errorf("unexpected type for Document: %T", extDocument)
 				}
 			}
-			g.addPathsToDocument(d, file.Services)
+			if err := g.addPathsToDocument(d, file.Services); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -147,6 +323,9 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 		}
 		g.reflect.requiredSchemas = g.reflect.requiredSchemas[count:len(g.reflect.requiredSchemas)]
 	}
+	if g.schemaNameErr != nil {
+		return nil, g.schemaNameErr
+	}
 
 	// If there is only 1 service, then use it's title for the
 	// document, if the document is missing it.
@@ -160,49 +339,26 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 		d.Tags[0].Description = ""
 	}
 
+	addOperationTagsToDocument(d)
+
 	var allServers []string
 
 	// If paths methods has servers, but they're all the same, then move servers to path level
 	for _, path := range d.Paths.Path {
 		var servers []string
+		operations := getPathItemOperations(path.Value)
 		// Only 1 server will ever be set, per method, by the generator
-		if path.Value.Get != nil && len(path.Value.Get.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Get.Servers[0].Url)
-			allServers = common.AppendUnique(allServers, path.Value.Get.Servers[0].Url)
-		}
-		if path.Value.Post != nil && len(path.Value.Post.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Post.Servers[0].Url)
-			allServers = common.AppendUnique(allServers, path.Value.Post.Servers[0].Url)
-		}
-		if path.Value.Put != nil && len(path.Value.Put.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Put.Servers[0].Url)
-			allServers = common.AppendUnique(allServers, path.Value.Put.Servers[0].Url)
-		}
-		if path.Value.Delete != nil && len(path.Value.Delete.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Delete.Servers[0].Url)
-			allServers = common.AppendUnique(allServers, path.Value.Delete.Servers[0].Url)
-		}
-		if path.Value.Patch != nil && len(path.Value.Patch.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Patch.Servers[0].Url)
-			allServers = common.AppendUnique(allServers, path.Value.Patch.Servers[0].Url)
+		for _, op := range operations {
+			if len(op.Servers) == 1 {
+				servers = common.AppendUnique(servers, op.Servers[0].Url)
+				allServers = common.AppendUnique(allServers, op.Servers[0].Url)
+			}
 		}
 
 		if len(servers) == 1 {
 			path.Value.Servers = []*openapi.Server{{Url: servers[0]}}
-			if path.Value.Get != nil {
-				path.Value.Get.Servers = nil
-			}
-			if path.Value.Post != nil {
-				path.Value.Post.Servers = nil
-			}
-			if path.Value.Put != nil {
-				path.Value.Put.Servers = nil
-			}
-			if path.Value.Delete != nil {
-				path.Value.Delete.Servers = nil
-			}
-			if path.Value.Patch != nil {
-				path.Value.Patch.Servers = nil
+			for _, op := range operations {
+				op.Servers = nil
 			}
 		}
 	}
@@ -223,13 +379,7 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	}
 
 	// Sort the tags.
-	{
-		pairs := d.Tags
-		sort.Slice(pairs, func(i, j int) bool {
-			return pairs[i].Name < pairs[j].Name
-		})
-		d.Tags = pairs
-	}
+	sortTags(d.Tags, declaredTags)
 	// Sort the paths.
 	{
 		pairs := d.Paths.Path
@@ -246,12 +396,22 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 		})
 		d.Components.Schemas.AdditionalProperties = pairs
 	}
-	return d
+	return d, nil
+}
+
+// getOperationID returns the operation ID of the method of the service formatted by the operation ID template.
+func (g *OpenAPIGenerator) getOperationID(service, method string) string {
+	operationID, err := common.FormatOperationID(g.operationIDTemplate, service, method)
+	if err != nil {
+		logs.Errorf("Error formatting operation id of '%s': %s", method, err)
+		return service + "_" + method
+	}
+	return operationID
 }
 
 // filterCommentString removes linter rules from comments.
 func (g *OpenAPIGenerator) filterCommentString(c protogen.Comments) string {
-	comment := regexp.MustCompile(consts.LinterRulePatternRegexp).ReplaceAllString(string(c), "")
+	comment := linterRulePattern.ReplaceAllString(string(c), "")
 	return strings.TrimSpace(comment)
 }
 
@@ -273,7 +433,7 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message, bod
 	var required []string
 	for _, field := range inputMessage.Fields {
 		if ext := proto.GetExtension(field.Desc.Options(), bodyType); ext != "" {
-			if common.Contains(allRequired, ext.(string)) {
+			if common.Contains(allRequired, ext.(string)) && !hasExplicitPresence(field.Desc) {
 				required = append(required, ext.(string))
 			}
 
@@ -293,7 +453,10 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message, bod
 						case annotations.FieldBehavior_INPUT_ONLY:
 							inputOnly = true
 						case annotations.FieldBehavior_REQUIRED:
-							required = append(required, g.reflect.formatFieldName(field.Desc))
+							// An `optional` field may be absent, so it is never required
+							if !hasExplicitPresence(field.Desc) {
+								required = append(required, g.reflect.formatFieldName(field.Desc))
+							}
 						}
 					}
 				default:
@@ -321,6 +484,11 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message, bod
 				schema.Schema.Description = description
 				schema.Schema.ReadOnly = outputOnly
 				schema.Schema.WriteOnly = inputOnly
+				schema.Schema.Nullable = hasExplicitPresence(field.Desc)
+				if isNullValue(field.Desc) {
+					// The JSON null is the only value of google.protobuf.NullValue, whatever the presence of the field
+					schema.Schema.Nullable = true
+				}
 
 				// Merge any `Property` annotations with the current
 				extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
@@ -328,6 +496,7 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message, bod
 					proto.Merge(schema.Schema, extProperty.(*openapi.Schema))
 				}
 			}
+			g.synthesizeExample(fieldSchema)
 			extName := proto.GetExtension(field.Desc.Options(), bodyType).(string)
 			if extName == "" {
 				extName = g.reflect.formatFieldName(field.Desc)
@@ -352,6 +521,7 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message, bod
 	if extSchema != nil {
 		proto.Merge(schema, extSchema.(*openapi.Schema))
 	}
+	g.closeSchema(schema)
 
 	schema.Required = required
 	return schema
@@ -376,90 +546,15 @@ func (g *OpenAPIGenerator) buildOperation(
 	if inputMessage != nil {
 		// Iterate through each field in the input message
 		for _, field := range inputMessage.Fields {
-			var paramName, paramIn, paramDesc string
-			var fieldSchema *openapi.SchemaOrReference
-			required := false
-			var ext any
-			// Check for each type of extension (query, path, cookie, header)
-			if ext = proto.GetExtension(field.Desc.Options(), api.E_Query); ext != "" {
-				paramName = proto.GetExtension(field.Desc.Options(), api.E_Query).(string)
-				paramIn = consts.ParameterInQuery
-				paramDesc = g.filterCommentString(field.Comments.Leading)
-				fieldSchema = g.reflect.schemaOrReferenceForField(field.Desc)
-				if schema, ok := fieldSchema.Oneof.(*openapi.SchemaOrReference_Schema); ok {
-					// Merge any `Property` annotations with the current
-					extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
-					if extProperty != nil {
-						if property, ok := extProperty.(*openapi.Schema); ok {
-							proto.Merge(schema.Schema, property)
-						} else {
-							logs.Errorf("unexpected type for Property: %T", extProperty)
-						}
-					}
-				}
-			} else if ext = proto.GetExtension(field.Desc.Options(), api.E_Path); ext != "" {
-				paramName = proto.GetExtension(field.Desc.Options(), api.E_Path).(string)
-				paramIn = consts.ParameterInPath
-				paramDesc = g.filterCommentString(field.Comments.Leading)
-				fieldSchema = g.reflect.schemaOrReferenceForField(field.Desc)
-				if schema, ok := fieldSchema.Oneof.(*openapi.SchemaOrReference_Schema); ok {
-					// Merge any `Property` annotations with the current
-					extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
-					if extProperty != nil {
-						proto.Merge(schema.Schema, extProperty.(*openapi.Schema))
-					}
-				}
-				// According to the OpenAPI specification, if a path parameter exists, it must be required.
-				required = true
-			} else if ext = proto.GetExtension(field.Desc.Options(), api.E_Cookie); ext != "" {
-				paramName = proto.GetExtension(field.Desc.Options(), api.E_Cookie).(string)
-				paramIn = consts.ParameterInCookie
-				paramDesc = g.filterCommentString(field.Comments.Leading)
-				fieldSchema = g.reflect.schemaOrReferenceForField(field.Desc)
-				if schema, ok := fieldSchema.Oneof.(*openapi.SchemaOrReference_Schema); ok {
-					// Merge any `Property` annotations with the current
-					extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
-					if extProperty != nil {
-						proto.Merge(schema.Schema, extProperty.(*openapi.Schema))
-					}
-				}
-			} else if ext = proto.GetExtension(field.Desc.Options(), api.E_Header); ext != "" {
-				paramName = proto.GetExtension(field.Desc.Options(), api.E_Header).(string)
-				paramIn = consts.ParameterInHeader
-				paramDesc = g.filterCommentString(field.Comments.Leading)
-				fieldSchema = g.reflect.schemaOrReferenceForField(field.Desc)
-				if schema, ok := fieldSchema.Oneof.(*openapi.SchemaOrReference_Schema); ok {
-					// Merge any `Property` annotations with the current
-					extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
-					if extProperty != nil {
-						proto.Merge(schema.Schema, extProperty.(*openapi.Schema))
-					}
-				}
-			}
-			parameter := &openapi.Parameter{
-				Name:        paramName,
-				In:          paramIn,
-				Description: paramDesc,
-				Required:    required,
-				Schema:      fieldSchema,
-			}
-			extParameter := proto.GetExtension(field.Desc.Options(), openapi.E_Parameter)
-			if extParameter != nil {
-				if parameterExt, ok := extParameter.(*openapi.Parameter); ok {
-					proto.Merge(parameter, parameterExt)
-				} else {
-					logs.Errorf("unexpected type for Parameter: %T", extParameter)
-				}
-			}
-
-			// Append the parameter to the parameters array if it was set
-			if paramName != "" && paramIn != "" {
-				parameters = append(parameters, &openapi.ParameterOrReference{
-					Oneof: &openapi.ParameterOrReference_Parameter{
-						Parameter: parameter,
-					},
-				})
+			paramName, paramIn := getParameterBinding(field)
+			if paramName == "" || paramIn == "" {
+				continue
 			}
+			parameters = append(parameters, &openapi.ParameterOrReference{
+				Oneof: &openapi.ParameterOrReference_Parameter{
+					Parameter: g.buildFieldParameter(field, paramName, paramIn),
+				},
+			})
 		}
 
 		if methodName != consts.HttpMethodGet && methodName != consts.HttpMethodHead && methodName != consts.HttpMethodDelete {
@@ -470,13 +565,13 @@ func (g *OpenAPIGenerator) buildOperation(
 			if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 
 				bodyRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixBody,
+					Name:  g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.BodySchemaSuffix,
 					Value: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: bodySchema}},
 				}
 
-				bodyRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixBody
+				bodyRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.BodySchemaSuffix
 
-				g.addSchemaToDocument(d, bodyRefSchema)
+				g.addDerivedSchemaToDocument(d, bodyRefSchema, "body of "+g.reflect.formatMessageName(inputMessage.Desc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 					Name: consts.ContentTypeJSON,
@@ -494,13 +589,13 @@ func (g *OpenAPIGenerator) buildOperation(
 
 			if formSchema != nil && formSchema.Properties != nil && len(formSchema.Properties.AdditionalProperties) > 0 {
 				formRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixForm,
+					Name:  g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.FormSchemaSuffix,
 					Value: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: formSchema}},
 				}
 
-				formRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixForm
+				formRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.FormSchemaSuffix
 
-				g.addSchemaToDocument(d, formRefSchema)
+				g.addDerivedSchemaToDocument(d, formRefSchema, "form of "+g.reflect.formatMessageName(inputMessage.Desc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 					Name: consts.ContentTypeFormMultipart,
@@ -529,13 +624,13 @@ func (g *OpenAPIGenerator) buildOperation(
 
 			if rawBodySchema != nil && rawBodySchema.Properties != nil && len(rawBodySchema.Properties.AdditionalProperties) > 0 {
 				rawBodyRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixRawBody,
+					Name:  g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.RawBodySchemaSuffix,
 					Value: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: rawBodySchema}},
 				}
 
-				rawBodyRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + consts.ComponentSchemaSuffixRawBody
+				rawBodyRef := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(inputMessage.Desc) + *g.conf.RawBodySchemaSuffix
 
-				g.addSchemaToDocument(d, rawBodyRefSchema)
+				g.addDerivedSchemaToDocument(d, rawBodyRefSchema, "raw body of "+g.reflect.formatMessageName(inputMessage.Desc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 					Name: consts.ContentTypeRawBody,
@@ -557,6 +652,7 @@ func (g *OpenAPIGenerator) buildOperation(
 							Content: &openapi.MediaTypes{
 								AdditionalProperties: additionalProperties,
 							},
+							Required: isRequestBodyRequired(bodySchema, formSchema, rawBodySchema),
 						},
 					},
 				}
@@ -607,13 +703,26 @@ func (g *OpenAPIGenerator) buildOperation(
 	re := regexp.MustCompile(`:(\w+)`)
 	path = re.ReplaceAllString(path, `{$1}`)
 
+	return newOperation(tagName, description, operationID, defaultHost, parameters, RequestBody, responses), path
+}
+
+// newOperation builds the operation of a method from the parts generated from its annotations.
+func newOperation(
+	tagName string,
+	description string,
+	operationID string,
+	defaultHost string,
+	parameters []*openapi.ParameterOrReference,
+	requestBody *openapi.RequestBodyOrReference,
+	responses *openapi.Responses,
+) *openapi.Operation {
 	op := &openapi.Operation{
 		Tags:        []string{tagName},
 		Description: description,
 		OperationId: operationID,
 		Parameters:  parameters,
 		Responses:   responses,
-		RequestBody: RequestBody,
+		RequestBody: requestBody,
 	}
 	if defaultHost != "" {
 		if !strings.HasPrefix(defaultHost, consts.URLDefaultPrefixHTTP) && !strings.HasPrefix(defaultHost, consts.URLDefaultPrefixHTTPS) {
@@ -621,8 +730,69 @@ func (g *OpenAPIGenerator) buildOperation(
 		}
 		op.Servers = append(op.Servers, &openapi.Server{Url: defaultHost})
 	}
+	return op
+}
 
-	return op, path
+// getParameterBinding returns the name and the location of the parameter the field is bound to
+// by the api.query, api.path, api.cookie or api.header annotation.
+func getParameterBinding(field *protogen.Field) (string, string) {
+	for _, binding := range []struct {
+		extension *protoimpl.ExtensionInfo
+		in        string
+	}{
+		{api.E_Query, consts.ParameterInQuery},
+		{api.E_Path, consts.ParameterInPath},
+		{api.E_Cookie, consts.ParameterInCookie},
+		{api.E_Header, consts.ParameterInHeader},
+	} {
+		if name := proto.GetExtension(field.Desc.Options(), binding.extension).(string); name != "" {
+			return name, binding.in
+		}
+	}
+	return "", ""
+}
+
+// buildFieldParameter builds the parameter of the field in the location, with the `Property` and
+// `Parameter` annotations of the field merged.
+func (g *OpenAPIGenerator) buildFieldParameter(field *protogen.Field, name, in string) *openapi.Parameter {
+	fieldSchema := g.reflect.schemaOrReferenceForField(field.Desc)
+	if schema, ok := fieldSchema.Oneof.(*openapi.SchemaOrReference_Schema); ok {
+		// Merge any `Property` annotations with the current
+		extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
+		if extProperty != nil {
+			if property, ok := extProperty.(*openapi.Schema); ok {
+				proto.Merge(schema.Schema, property)
+			} else {
+				logs.Errorf("unexpected type for Property: %T", extProperty)
+			}
+		}
+	}
+	g.synthesizeExample(fieldSchema)
+	parameter := &openapi.Parameter{
+		Name:        name,
+		In:          in,
+		Description: g.filterCommentString(field.Comments.Leading),
+		// According to the OpenAPI specification, if a path parameter exists, it must be required.
+		Required: in == consts.ParameterInPath,
+		Schema:   fieldSchema,
+	}
+	setParameterStyle(parameter)
+	if in == consts.ParameterInCookie && isComplexParameter(parameter) {
+		logs.Warnf("cookie parameter '%s' of message '%s' is not a primitive type, a cookie only carries a string", name, field.Parent.Desc.Name())
+	}
+	extParameter := proto.GetExtension(field.Desc.Options(), openapi.E_Parameter)
+	if extParameter != nil {
+		if parameterExt, ok := extParameter.(*openapi.Parameter); ok {
+			proto.Merge(parameter, parameterExt)
+			// A style set by the annotation comes with its own explode, which may be false
+			if parameterExt.GetStyle() != "" {
+				parameter.Explode = parameterExt.GetExplode()
+			}
+		} else {
+			logs.Errorf("unexpected type for Parameter: %T", extParameter)
+		}
+	}
+	return parameter
 }
 
 func (g *OpenAPIGenerator) getResponseForMessage(d *openapi.Document, message *protogen.Message) (string, *openapi.HeadersOrReferences, *openapi.MediaTypes) {
@@ -635,6 +805,7 @@ func (g *OpenAPIGenerator) getResponseForMessage(d *openapi.Document, message *p
 				Description: g.filterCommentString(field.Comments.Leading),
 				Schema:      g.reflect.schemaOrReferenceForField(field.Desc),
 			}
+			g.synthesizeExample(header.Schema)
 			headers.AdditionalProperties = append(headers.AdditionalProperties, &openapi.NamedHeaderOrReference{
 				Name: headerName,
 				Value: &openapi.HeaderOrReference{
@@ -654,11 +825,11 @@ func (g *OpenAPIGenerator) getResponseForMessage(d *openapi.Document, message *p
 
 	if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  g.reflect.formatMessageName(message.Desc) + consts.ComponentSchemaSuffixBody,
+			Name:  g.reflect.formatMessageName(message.Desc) + *g.conf.BodySchemaSuffix,
 			Value: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: bodySchema}},
 		}
-		ref := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(message.Desc) + consts.ComponentSchemaSuffixBody
-		g.addSchemaToDocument(d, refSchema)
+		ref := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(message.Desc) + *g.conf.BodySchemaSuffix
+		g.addDerivedSchemaToDocument(d, refSchema, "body of "+g.reflect.formatMessageName(message.Desc))
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 			Name: consts.ContentTypeJSON,
 			Value: &openapi.MediaType{
@@ -673,11 +844,11 @@ func (g *OpenAPIGenerator) getResponseForMessage(d *openapi.Document, message *p
 
 	if rawBodySchema != nil && rawBodySchema.Properties != nil && len(rawBodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  g.reflect.formatMessageName(message.Desc) + consts.ComponentSchemaSuffixRawBody,
+			Name:  g.reflect.formatMessageName(message.Desc) + *g.conf.RawBodySchemaSuffix,
 			Value: &openapi.SchemaOrReference{Oneof: &openapi.SchemaOrReference_Schema{Schema: rawBodySchema}},
 		}
-		ref := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(message.Desc) + consts.ComponentSchemaSuffixRawBody
-		g.addSchemaToDocument(d, refSchema)
+		ref := consts.ComponentSchemaPrefix + g.reflect.formatMessageName(message.Desc) + *g.conf.RawBodySchemaSuffix
+		g.addDerivedSchemaToDocument(d, refSchema, "raw body of "+g.reflect.formatMessageName(message.Desc))
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 			Name: consts.ContentTypeRawBody,
 			Value: &openapi.MediaType{
@@ -697,8 +868,20 @@ func (g *OpenAPIGenerator) getResponseForMessage(d *openapi.Document, message *p
 	return consts.StatusOK, headers, content
 }
 
+// getPathItemOperations returns the operations set on the path item.
+func getPathItemOperations(item *openapi.PathItem) []*openapi.Operation {
+	var operations []*openapi.Operation
+	for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Delete, item.Patch, item.Options, item.Head} {
+		if op != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
 // addOperationToDocument adds an operation to the specified path/method.
 func (g *OpenAPIGenerator) addOperationToDocument(d *openapi.Document, op *openapi.Operation, path, methodName string) {
+	path = common.WithBasePath(*g.conf.BasePath, path)
 	var selectedPathItem *openapi.NamedPathItem
 	for _, namedPathItem := range d.Paths.Path {
 		if namedPathItem.Name == path {
@@ -730,7 +913,9 @@ func (g *OpenAPIGenerator) addOperationToDocument(d *openapi.Document, op *opena
 	}
 }
 
-func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*protogen.Service) {
+// addPathsToDocument adds the operations of the methods of the services to the document. It fails
+// on a google.api.http path template that can't be parsed.
+func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*protogen.Service) error {
 	for _, service := range services {
 		annotationsCount := 0
 
@@ -738,23 +923,50 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*p
 			comment := g.filterCommentString(method.Comments.Leading)
 			inputMessage := method.Input
 			outputMessage := method.Output
-			operationID := service.GoName + "_" + method.GoName
+			operationID := g.getOperationID(service.GoName, method.GoName)
+			host := proto.GetExtension(method.Desc.Options(), api.E_Baseurl).(string)
+			if host == "" {
+				host = proto.GetExtension(service.Desc.Options(), api.E_BaseDomain).(string)
+			}
+			if *g.conf.HttpAnnotation == consts.HttpAnnotationGoogle {
+				for i, rule := range getHttpRules(method) {
+					annotationsCount++
+					// Each additional binding is a separate operation, which needs its own ID
+					ruleOperationID := operationID
+					if i > 0 {
+						ruleOperationID += "_" + strconv.Itoa(i)
+					}
+					op, path, err := g.buildGoogleOperation(d, rule, ruleOperationID, service.GoName, comment, host, inputMessage, outputMessage)
+					if err != nil {
+						return fmt.Errorf("method '%s': %s", method.Desc.FullName(), err.Error())
+					}
+					// Merge any `Operation` annotations with the current
+					extOperation := proto.GetExtension(method.Desc.Options(), openapi.E_Operation)
+					if extOperation != nil {
+						proto.Merge(op, extOperation.(*openapi.Operation))
+						mergeOperationContent(op)
+					}
+					if err = addSunsetHeader(op); err != nil {
+						return fmt.Errorf("method '%s': %s", method.Desc.FullName(), err.Error())
+					}
+					g.addOperationToDocument(d, op, path, rule.method)
+				}
+				continue
+			}
 			rs := api.GetAllOptions(api.HttpMethodOptions, method.Desc.Options())
 			for methodName, path := range rs {
 				if methodName != "" {
 					annotationsCount++
-					var host string
-					host = proto.GetExtension(method.Desc.Options(), api.E_Baseurl).(string)
-
-					if host == "" {
-						host = proto.GetExtension(service.Desc.Options(), api.E_BaseDomain).(string)
-					}
 					op, path2 := g.buildOperation(d, methodName, operationID, service.GoName, comment, host, path.(string), inputMessage, outputMessage)
 					// Merge any `Operation` annotations with the current
 					extOperation := proto.GetExtension(method.Desc.Options(), openapi.E_Operation)
 
 					if extOperation != nil {
 						proto.Merge(op, extOperation.(*openapi.Operation))
+						mergeOperationContent(op)
+					}
+					if err := addSunsetHeader(op); err != nil {
+						return fmt.Errorf("method '%s': %s", method.Desc.FullName(), err.Error())
 					}
 					g.addOperationToDocument(d, op, path2, methodName)
 				}
@@ -762,9 +974,86 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*p
 		}
 		if annotationsCount > 0 {
 			comment := g.filterCommentString(service.Comments.Leading)
-			d.Tags = append(d.Tags, &openapi.Tag{Name: service.GoName, Description: comment})
+			addTagToDocument(d, &openapi.Tag{Name: service.GoName, Description: comment})
 		}
 	}
+	return nil
+}
+
+// addTagToDocument adds the tag of a service to the document, a tag with the same name declared
+// in the openapi.document annotation is kept and only takes the description if it has none.
+func addTagToDocument(d *openapi.Document, tag *openapi.Tag) {
+	for _, t := range d.Tags {
+		if t.Name == tag.Name {
+			if t.Description == "" {
+				t.Description = tag.Description
+			}
+			return
+		}
+	}
+	d.Tags = append(d.Tags, tag)
+}
+
+// addOperationTagsToDocument adds the tags the operations carry besides the tags of their services, e.g.
+// admin or beta, to the document, so that every tag referenced by an operation is listed. The tags of
+// openapi.operation are appended to the tag of the service when merged, so the duplicates are dropped.
+func addOperationTagsToDocument(d *openapi.Document) {
+	for _, path := range d.Paths.Path {
+		for _, op := range getPathItemOperations(path.Value) {
+			var tags []string
+			for _, tag := range op.Tags {
+				tags = common.AppendUnique(tags, tag)
+			}
+			op.Tags = tags
+			for _, tag := range op.Tags {
+				addTagToDocument(d, &openapi.Tag{Name: tag})
+			}
+		}
+	}
+}
+
+// sortTags sorts the tags declared in the openapi.document annotation first, in their declared
+// order, followed by the other tags in alphabetical order.
+func sortTags(tags []*openapi.Tag, declared []string) {
+	order := make(map[string]int, len(declared))
+	for i, name := range declared {
+		if _, ok := order[name]; !ok {
+			order[name] = i
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		oi, iok := order[tags[i].Name]
+		oj, jok := order[tags[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		if iok || jok {
+			return iok
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// claimSchemaName records the origin of the schema name, and reports whether the name is not
+// already generated from another origin, e.g. a message named after the body schema of another message.
+// The first collision is kept in schemaNameErr to fail the document.
+func (g *OpenAPIGenerator) claimSchemaName(name, origin string) bool {
+	if claimed, ok := g.schemaOrigins[name]; ok && claimed != origin {
+		if g.schemaNameErr == nil {
+			g.schemaNameErr = fmt.Errorf("schema name %s of the %s collides with the %s, set another suffix with the body_schema_suffix, form_schema_suffix or raw_body_schema_suffix option", name, origin, claimed)
+		}
+		return false
+	}
+	g.schemaOrigins[name] = origin
+	return true
+}
+
+// addDerivedSchemaToDocument adds the body, form or raw body schema derived from a message to the
+// document, unless its name collides with a schema of another origin, which fails the document.
+func (g *OpenAPIGenerator) addDerivedSchemaToDocument(d *openapi.Document, schema *openapi.NamedSchemaOrReference, origin string) {
+	if g.claimSchemaName(schema.Name, origin) {
+		g.addSchemaToDocument(d, schema)
+	}
 }
 
 // addSchemaToDocument adds the schema to the document if required
@@ -776,6 +1065,117 @@ func (g *OpenAPIGenerator) addSchemaToDocument(d *openapi.Document, schema *open
 	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, schema)
 }
 
+// addSunsetHeader documents the Sunset header of the successful responses of a deprecated operation
+// whose x-sunset extension, set by the openapi.operation option, holds the date it may stop working.
+// It fails if the date isn't an HTTP date, e.g. `Wed, 31 Dec 2025 23:59:59 GMT`.
+func addSunsetHeader(op *openapi.Operation) error {
+	if !op.GetDeprecated() {
+		return nil
+	}
+	var date *openapi.Any
+	for _, named := range op.GetSpecificationExtension() {
+		if named.GetName() == consts.ExtensionSunset {
+			date = named.GetValue()
+		}
+	}
+	if date == nil {
+		return nil
+	}
+	if _, err := common.ParseSunset(date.GetYaml()); err != nil {
+		return err
+	}
+	for _, response := range op.GetResponses().GetResponseOrReference() {
+		r := response.GetValue().GetResponse()
+		if !strings.HasPrefix(response.GetName(), "2") || r == nil {
+			continue
+		}
+		if r.Headers == nil {
+			r.Headers = &openapi.HeadersOrReferences{}
+		}
+		if hasHeader(r.Headers, consts.HeaderSunset) {
+			continue
+		}
+		r.Headers.AdditionalProperties = append(r.Headers.AdditionalProperties, &openapi.NamedHeaderOrReference{
+			Name: consts.HeaderSunset,
+			Value: &openapi.HeaderOrReference{
+				Oneof: &openapi.HeaderOrReference_Header{
+					Header: &openapi.Header{
+						Description: consts.SunsetHeaderDesc,
+						Schema: &openapi.SchemaOrReference{
+							Oneof: &openapi.SchemaOrReference_Schema{Schema: &openapi.Schema{Type: "string"}},
+						},
+						Example: proto.Clone(date).(*openapi.Any),
+					},
+				},
+			},
+		})
+	}
+	return nil
+}
+
+// hasHeader reports whether the headers contain one of the name, which is case-insensitive.
+func hasHeader(headers *openapi.HeadersOrReferences, name string) bool {
+	for _, header := range headers.GetAdditionalProperties() {
+		if strings.EqualFold(header.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// closeSchema disallows the properties not declared by the object schema when closed_schemas
+// is set, unless the openapi.schema annotation already sets additionalProperties.
+func (g *OpenAPIGenerator) closeSchema(schema *openapi.Schema) {
+	if *g.conf.ClosedSchemas && schema.AdditionalProperties == nil {
+		schema.AdditionalProperties = &openapi.AdditionalPropertiesItem{
+			Oneof: &openapi.AdditionalPropertiesItem_Boolean{Boolean: false},
+		}
+	}
+}
+
+// synthesizeExample fills the example of a primitive schema lacking one with a placeholder of its type, or
+// the first value of its enum, when synthesize_examples is set, so that Swagger UI prefills the fields.
+// The items of an array are filled the same way. The placeholder is moved into the bounds and length
+// limits of the schema, a string with a pattern is left alone, as the placeholder may not match it.
+func (g *OpenAPIGenerator) synthesizeExample(schemaOrReference *openapi.SchemaOrReference) {
+	if !*g.conf.SynthesizeExamples || schemaOrReference == nil {
+		return
+	}
+	schema := schemaOrReference.GetSchema()
+	if schema == nil {
+		return
+	}
+	for _, item := range schema.GetItems().GetSchemaOrReference() {
+		g.synthesizeExample(item)
+	}
+	if schema.Example != nil || schema.Pattern != "" {
+		return
+	}
+	if len(schema.Enum) > 0 {
+		schema.Example = &openapi.Any{Yaml: schema.Enum[0].GetYaml()}
+		return
+	}
+	// A zero bound or length is unset
+	var bounds common.ExampleBounds
+	if schema.Minimum != 0 || schema.ExclusiveMinimum {
+		bounds.Minimum = &schema.Minimum
+	}
+	if schema.Maximum != 0 || schema.ExclusiveMaximum {
+		bounds.Maximum = &schema.Maximum
+	}
+	bounds.ExclusiveMinimum = schema.ExclusiveMinimum
+	bounds.ExclusiveMaximum = schema.ExclusiveMaximum
+	if schema.MinLength != 0 {
+		bounds.MinLength = &schema.MinLength
+	}
+	if schema.MaxLength != 0 {
+		bounds.MaxLength = &schema.MaxLength
+	}
+	if example := common.ExampleForType(schema.Type, schema.Format, bounds); example != "" {
+		schema.Example = &openapi.Any{Yaml: example}
+	}
+}
+
 // addSchemasForMessagesToDocument adds info from one file descriptor.
 func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document, messages []*protogen.Message) {
 	// For each message, generate a definition.
@@ -788,6 +1188,7 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 
 		// Only generate this if we need it and haven't already generated it.
 		if !common.Contains(g.reflect.requiredSchemas, schemaName) ||
+			!g.claimSchemaName(schemaName, "message "+schemaName) ||
 			common.Contains(g.generatedSchemas, schemaName) {
 			continue
 		}
@@ -833,7 +1234,10 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 						case annotations.FieldBehavior_INPUT_ONLY:
 							inputOnly = true
 						case annotations.FieldBehavior_REQUIRED:
-							required = append(required, g.reflect.formatFieldName(field.Desc))
+							// An `optional` field may be absent, so it is never required
+							if !hasExplicitPresence(field.Desc) {
+								required = append(required, g.reflect.formatFieldName(field.Desc))
+							}
 						}
 					}
 				default:
@@ -861,6 +1265,11 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 				schema.Schema.Description = description
 				schema.Schema.ReadOnly = outputOnly
 				schema.Schema.WriteOnly = inputOnly
+				schema.Schema.Nullable = hasExplicitPresence(field.Desc)
+				if isNullValue(field.Desc) {
+					// The JSON null is the only value of google.protobuf.NullValue, whatever the presence of the field
+					schema.Schema.Nullable = true
+				}
 
 				// Merge any `Property` annotations with the current
 				extProperty := proto.GetExtension(field.Desc.Options(), openapi.E_Property)
@@ -868,6 +1277,7 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 					proto.Merge(schema.Schema, extProperty.(*openapi.Schema))
 				}
 			}
+			g.synthesizeExample(fieldSchema)
 			var name string
 			if ext := proto.GetExtension(field.Desc.Options(), api.E_Header); ext != "" {
 				name = proto.GetExtension(field.Desc.Options(), api.E_Header).(string)
@@ -905,6 +1315,7 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 		if extSchema != nil {
 			proto.Merge(schema, extSchema.(*openapi.Schema))
 		}
+		g.closeSchema(schema)
 
 		// Add the schema to the components.schema list.
 		g.addSchemaToDocument(d, &openapi.NamedSchemaOrReference{
@@ -917,3 +1328,97 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 		})
 	}
 }
+
+// setParameterStyle sets the serialization style of an array parameter to the default of its location,
+// so that clients know whether the values are repeated or joined.
+func setParameterStyle(parameter *openapi.Parameter) {
+	schema := parameter.GetSchema()
+	if !(schema.GetSchema().GetType() == "array") {
+		return
+	}
+	switch parameter.In {
+	case consts.ParameterInQuery, consts.ParameterInCookie:
+		parameter.Style = "form"
+		parameter.Explode = true
+	case consts.ParameterInPath, consts.ParameterInHeader:
+		parameter.Style = "simple"
+	}
+}
+
+// isComplexParameter reports whether the parameter is an array or an object rather than a primitive value.
+func isComplexParameter(parameter *openapi.Parameter) bool {
+	schema := parameter.GetSchema()
+	return schema.GetReference() != nil || schema.GetSchema().GetType() == "array" || schema.GetSchema().GetType() == consts.SchemaObjectType
+}
+
+// isRequestBodyRequired reports whether a field bound to the request body is required,
+// that is the `required` of one of the body schemas isn't empty.
+func isRequestBodyRequired(schemas ...*openapi.Schema) bool {
+	for _, schema := range schemas {
+		if schema != nil && len(schema.Required) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOperationContent merges the media types and responses declared again by an `Operation`
+// annotation into the generated ones, so that e.g. examples can be attached to them.
+func mergeOperationContent(op *openapi.Operation) {
+	if requestBody := op.GetRequestBody().GetRequestBody(); requestBody != nil {
+		mergeMediaTypes(requestBody.Content)
+	}
+	if op.Responses == nil {
+		return
+	}
+	var responses []*openapi.NamedResponseOrReference
+	for _, response := range op.Responses.ResponseOrReference {
+		merged := false
+		for _, r := range responses {
+			if r.Name == response.Name {
+				if r.Value == nil {
+					r.Value = response.Value
+				} else if response.Value != nil {
+					proto.Merge(r.Value, response.Value)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			responses = append(responses, response)
+		}
+	}
+	op.Responses.ResponseOrReference = responses
+	for _, response := range responses {
+		if r := response.GetValue().GetResponse(); r != nil {
+			mergeMediaTypes(r.Content)
+		}
+	}
+}
+
+// mergeMediaTypes merges the media types with the same name into the first of them.
+func mergeMediaTypes(content *openapi.MediaTypes) {
+	if content == nil {
+		return
+	}
+	var mediaTypes []*openapi.NamedMediaType
+	for _, mediaType := range content.AdditionalProperties {
+		merged := false
+		for _, m := range mediaTypes {
+			if m.Name == mediaType.Name {
+				if m.Value == nil {
+					m.Value = mediaType.Value
+				} else if mediaType.Value != nil {
+					proto.Merge(m.Value, mediaType.Value)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	content.AdditionalProperties = mediaTypes
+}
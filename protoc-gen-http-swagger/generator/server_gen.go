@@ -31,6 +31,8 @@ import (
 
 type ServerGenerator struct {
 	IdlPath string
+	// SplitSchemas is only supported by the thrift generators, it's kept for the shared template
+	SplitSchemas bool
 }
 
 func NewServerGenerator(inputFiles []*protogen.File) (*ServerGenerator, error) {
@@ -35,6 +35,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -48,13 +49,27 @@ var flags flag.FlagSet
 
 func main() {
 	conf := generator.Configuration{
-		Version:        flags.String("version", "3.0.3", "version number text, e.g. 1.2.3"),
-		Title:          flags.String("title", "", "name of the API"),
-		Description:    flags.String("description", "", "description of the API"),
-		Naming:         flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
-		FQSchemaNaming: flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
-		EnumType:       flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
-		OutputMode:     flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative' to generate a separate '[inputfile].openapi.yaml' next to each '[inputfile].proto'.`),
+		Version:             flags.String("version", "3.0.3", "version number text, e.g. 1.2.3"),
+		Title:               flags.String("title", "", "name of the API"),
+		Description:         flags.String("description", "", "description of the API"),
+		Naming:              flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
+		FQSchemaNaming:      flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
+		InlineSchemas:       flags.Bool("inline_schemas", false, `inline the schemas referenced only once at the place of the reference instead of in components`),
+		PruneUnused:         flags.Bool("prune_unused", false, `remove the component schemas that are not referenced by the paths or the other components`),
+		ClosedSchemas:       flags.Bool("closed_schemas", false, `set additionalProperties to false on the object schemas of the messages to reject the undeclared properties`),
+		EnumType:            flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
+		OutputMode:          flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative" to generate a separate "[inputfile].openapi.yaml" next to each "[inputfile].proto", or "both" to generate them along with the single openapi.yaml.`),
+		FileHeader:          flags.String("file_header", "", "file whose content is prepended as comments to the generated YAML files, e.g. a license header"),
+		OperationIDTemplate: flags.String("operation_id_template", consts.DefaultOperationIDTemplate, `Go template of the operation IDs, using .Service and .Method and the lowerFirst and upperFirst functions, e.g. "{{lowerFirst .Method}}"`),
+		OpenAPIVersion:      flags.String("openapi_version", consts.OpenAPIVersion, `version of the OpenAPI specification of the document. Use "3.1.0" to generate an OpenAPI 3.1 document`),
+		BodySchemaSuffix:    flags.String("body_schema_suffix", consts.ComponentSchemaSuffixBody, "suffix of the names of the body schemas generated from the messages"),
+		FormSchemaSuffix:    flags.String("form_schema_suffix", consts.ComponentSchemaSuffixForm, "suffix of the names of the form schemas generated from the messages"),
+		RawBodySchemaSuffix: flags.String("raw_body_schema_suffix", consts.ComponentSchemaSuffixRawBody, "suffix of the names of the raw body schemas generated from the messages"),
+		HttpAnnotation:      flags.String("http_annotation", consts.HttpAnnotationHertz, `annotations declaring the HTTP bindings of the methods. Use "google" to read the google.api.http annotations of grpc-gateway instead of the hertz api annotations`),
+		Validate:            flags.Bool("validate", false, `validate the generated document against the OpenAPI 3 specification and fail if it is invalid`),
+		SynthesizeExamples:  flags.Bool("synthesize_examples", false, `fill the examples of the primitive schemas lacking one with placeholders of their types, e.g. "string", 0 or true`),
+		BasePath:            flags.String("base_path", "", "path prefix of all the documented paths, e.g. /service-a for a service deployed behind a gateway"),
+		Swagger2:            flags.Bool("swagger2", false, `convert the generated document to Swagger 2.0 for the tools that don't read OpenAPI 3`),
 	}
 
 	opts := protogen.Options{
@@ -64,22 +79,38 @@ func main() {
 	opts.Run(func(plugin *protogen.Plugin) error {
 		// Enable "optional" keyword in front of type (e.g. optional string label = 1;)
 		plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		if *conf.OutputMode == "source_relative" {
+		if *conf.OpenAPIVersion != consts.OpenAPIVersion && *conf.OpenAPIVersion != consts.OpenAPIVersion31 {
+			return fmt.Errorf("unsupported openapi version %q, use %q or %q", *conf.OpenAPIVersion, consts.OpenAPIVersion, consts.OpenAPIVersion31)
+		}
+		if *conf.Swagger2 && *conf.OpenAPIVersion != consts.OpenAPIVersion {
+			return fmt.Errorf("swagger 2.0 is converted from openapi %s, but got openapi version %q", consts.OpenAPIVersion, *conf.OpenAPIVersion)
+		}
+		if *conf.HttpAnnotation != consts.HttpAnnotationHertz && *conf.HttpAnnotation != consts.HttpAnnotationGoogle {
+			return fmt.Errorf("unsupported http annotation %q, use %q or %q", *conf.HttpAnnotation, consts.HttpAnnotationHertz, consts.HttpAnnotationGoogle)
+		}
+		if *conf.OutputMode == consts.OutputModeSourceRelative || *conf.OutputMode == consts.OutputModeBoth {
 			for _, file := range plugin.Files {
 				if !file.Generate {
 					continue
 				}
 				outfileName := strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())) + "." + consts.DefaultOutputYamlFile
 				outputFile := plugin.NewGeneratedFile(outfileName, "")
-				gen := generator.NewOpenAPIGenerator(plugin, conf, []*protogen.File{file})
-				if err := gen.Run(outputFile); err != nil {
+				gen, err := generator.NewOpenAPIGenerator(plugin, conf, []*protogen.File{file})
+				if err != nil {
+					return err
+				}
+				if err = gen.Run(outputFile); err != nil {
 					return err
 				}
 			}
-		} else {
+		}
+		if *conf.OutputMode != consts.OutputModeSourceRelative {
 			outputFile := plugin.NewGeneratedFile(consts.DefaultOutputYamlFile, "")
-			gen := generator.NewOpenAPIGenerator(plugin, conf, plugin.Files)
-			if err := gen.Run(outputFile); err != nil {
+			gen, err := generator.NewOpenAPIGenerator(plugin, conf, plugin.Files)
+			if err != nil {
+				return err
+			}
+			if err = gen.Run(outputFile); err != nil {
 				return err
 			}
 		}
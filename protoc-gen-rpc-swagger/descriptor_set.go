@@ -0,0 +1,113 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// runDescriptorSet runs the generation on a serialized FileDescriptorSet instead of a request of protoc,
+// e.g. one written by `protoc --descriptor_set_out --include_imports --include_source_info`, so that
+// the compiled descriptors can be cached and protoc skipped. The generated files are written to the
+// output directory.
+func runDescriptorSet(opts protogen.Options, generate func(*protogen.Plugin) error, args []string) error {
+	cmd := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	in := cmd.String("descriptor_set_in", "", "serialized FileDescriptorSet of the proto files and their imports")
+	out := cmd.String("out", consts.DefaultOutputDir, "directory the generated files are written to")
+	opt := cmd.String("opt", "", `plugin options, as passed with --rpc-swagger_opt, e.g. "naming=proto,enum_type=string"`)
+	cmd.Usage = func() {
+		fmt.Fprintf(cmd.Output(), "usage: %s -descriptor_set_in=file [flags] [proto file...]\n", cmd.Name())
+		fmt.Fprintf(cmd.Output(), "The proto files default to the files of the set not imported by the other ones.\n")
+		cmd.PrintDefaults()
+	}
+	if err := cmd.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *in == "" {
+		return errors.New("the descriptor_set_in flag is required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return fmt.Errorf("failed to read descriptor set: %s", err.Error())
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, set); err != nil {
+		return fmt.Errorf("failed to parse descriptor set: %s", err.Error())
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: cmd.Args(),
+		Parameter:      proto.String(*opt),
+		ProtoFile:      set.File,
+	}
+	if len(req.FileToGenerate) == 0 {
+		req.FileToGenerate = rootFiles(set)
+	}
+
+	plugin, err := opts.New(req)
+	if err != nil {
+		return err
+	}
+	if err = generate(plugin); err != nil {
+		return err
+	}
+	resp := plugin.Response()
+	if resp.Error != nil {
+		return errors.New(resp.GetError())
+	}
+	for _, file := range resp.File {
+		path := filepath.Join(*out, file.GetName())
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %s", err.Error())
+		}
+		if err = os.WriteFile(path, []byte(file.GetContent()), 0o644); err != nil {
+			return fmt.Errorf("failed to write output file: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// rootFiles returns the files of the set that no other file of the set imports, which are the files
+// given to protoc when the set was written with --include_imports.
+func rootFiles(set *descriptorpb.FileDescriptorSet) []string {
+	imported := make(map[string]bool)
+	for _, file := range set.File {
+		for _, dependency := range file.Dependency {
+			imported[dependency] = true
+		}
+	}
+	var files []string
+	for _, file := range set.File {
+		if !imported[file.GetName()] {
+			files = append(files, file.GetName())
+		}
+	}
+	return files
+}
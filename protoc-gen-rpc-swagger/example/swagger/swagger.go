@@ -29,6 +29,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/gopkg/cloud/metainfo"
 	"github.com/cloudwego/dynamicgo/proto"
@@ -46,6 +49,7 @@ import (
 	"github.com/cloudwego/kitex/pkg/remote/trans/detection"
 	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
+	"github.com/cloudwego/kitex/pkg/retry"
 	"github.com/cloudwego/kitex/pkg/transmeta"
 	"github.com/cloudwego/kitex/transport"
 	"github.com/hertz-contrib/cors"
@@ -58,11 +62,23 @@ var (
 	openapiYAML []byte
 	hertzEngine *route.Engine
 	httpReg     = regexp.MustCompile("^(?:GET |POST|PUT|DELE|HEAD|OPTI|CONN|TRAC|PATC)$")
+	genericCli  genericclient.Client
+
+	// shuttingDown is set once Shutdown is called, clientMu is held for reading by the proxy calls
+	// so that Shutdown closes the generic client after the calls in flight
+	shuttingDown int32
+	clientMu     sync.RWMutex
+	clientClosed bool
 )
 
 const (
-	kitexAddr = "127.0.0.1:8888"
-	idlFile   = "hello.proto"
+	kitexAddr   = "127.0.0.1:8888"
+	idlFile     = "hello.proto"
+	proxyPrefix = "/api/"
+
+	// rpcTimeout bounds each call of the generic client, maxRetryTimes retries the failed calls, 0 disables them
+	rpcTimeout    = 5000 * time.Millisecond
+	maxRetryTimes = 0
 )
 
 type MixTransHandlerFactory struct {
@@ -96,6 +112,18 @@ func (m MixTransHandlerFactory) NewTransHandler(opt *remote.ServerOption) (remot
 	return &transHandler{ServerTransHandler: kitexOrigin}, nil
 }
 
+// GracefulShutdown closes the connections of the Kitex server gracefully, then shuts the proxy down.
+func (t *transHandler) GracefulShutdown(ctx context.Context) error {
+	var err error
+	if g, ok := t.ServerTransHandler.(remote.GracefulShutdown); ok {
+		err = g.GracefulShutdown(ctx)
+	}
+	if shutdownErr := Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
 func (t *transHandler) OnRead(ctx context.Context, conn net.Conn) error {
 	c, ok := conn.(network.Conn)
 	if ok {
@@ -117,12 +145,17 @@ func StartServer() {
 	h := server.Default()
 	h.Use(cors.Default())
 
-	cli := initializeGenericClient()
+	cli, err := initializeGenericClient()
+	if err != nil {
+		hlog.Errorf("Failed to initialize generic client: %v", err)
+	}
+	genericCli = cli
+	setupHealthRoutes(h, cli)
 	setupSwaggerRoutes(h)
 	setupProxyRoutes(h, cli)
 
-	hlog.Info("Swagger UI is available at: http://" + kitexAddr + "/swagger/index.html")
-	err := h.Engine.Init()
+	hlog.Info("Swagger UI is available at: http://" + getAddr("KITEX_ADDR", kitexAddr) + "/swagger/index.html")
+	err = h.Engine.Init()
 	if err != nil {
 		panic(err)
 	}
@@ -130,6 +163,34 @@ func StartServer() {
 	hertzEngine = h.Engine
 }
 
+// Shutdown gracefully stops the proxy: it shuts the HTTPS server down if any, then closes the
+// generic client once the proxy calls in flight are done. It is called by the Kitex server when it
+// shuts down gracefully, e.g. on SIGINT or SIGTERM.
+func Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&shuttingDown, 0, 1) {
+		return nil
+	}
+	var err error
+
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	clientClosed = true
+	if genericCli != nil {
+		if closeErr := genericCli.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// getAddr returns the address set in the environment variable, falling back to the generated one.
+func getAddr(env, addr string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return addr
+}
+
 func findPbFile(fileName string) (string, error) {
 	workingDir, err := os.Getwd()
 	if err != nil {
@@ -175,32 +236,68 @@ func findPbFile(fileName string) (string, error) {
 	return "", errors.New("proto file not found: " + fileName)
 }
 
-func initializeGenericClient() genericclient.Client {
+func initializeGenericClient() (genericclient.Client, error) {
 	pbFile, err := findPbFile(idlFile)
 	if err != nil {
-		hlog.Fatal("Failed to locate Proto file:", err)
+		return nil, fmt.Errorf("failed to locate Proto file: %w", err)
 	}
 
 	dOpts := proto.Options{}
 	p, err := generic.NewPbFileProviderWithDynamicGo(pbFile, context.Background(), dOpts)
 	if err != nil {
-		hlog.Fatal("Failed to create PbFileProvider:", err)
+		return nil, fmt.Errorf("failed to create PbFileProvider: %w", err)
 	}
 
 	g, err := generic.JSONPbGeneric(p)
 	if err != nil {
-		hlog.Fatal("Failed to create JsonPbGeneric:", err)
+		return nil, fmt.Errorf("failed to create JsonPbGeneric: %w", err)
 	}
 	var opts []client.Option
 	opts = append(opts, client.WithTransportProtocol(transport.TTHeader))
 	opts = append(opts, client.WithMetaHandler(transmeta.ClientTTHeaderHandler))
-	opts = append(opts, client.WithHostPorts(kitexAddr))
+	opts = append(opts, client.WithHostPorts(getAddr("KITEX_ADDR", kitexAddr)))
+	if rpcTimeout > 0 {
+		opts = append(opts, client.WithRPCTimeout(rpcTimeout))
+	}
+	if maxRetryTimes > 0 {
+		fp := retry.NewFailurePolicy()
+		fp.WithMaxRetryTimes(maxRetryTimes)
+		opts = append(opts, client.WithFailureRetry(fp))
+	}
 	cli, err := genericclient.NewClient("swagger", g, opts...)
 	if err != nil {
-		hlog.Fatal("Failed to create generic client:", err)
+		return nil, fmt.Errorf("failed to create generic client: %w", err)
 	}
 
-	return cli
+	return cli, nil
+}
+
+// setupHealthRoutes registers the liveness probe /healthz, and the readiness probe /readyz which
+// fails while the generic client calling the Kitex service is not initialized.
+func setupHealthRoutes(h *server.Hertz, cli genericclient.Client) {
+	h.GET("/healthz", func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
+
+	h.GET("/readyz", func(c context.Context, ctx *app.RequestContext) {
+		if atomic.LoadInt32(&shuttingDown) == 1 {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "shutting down",
+			})
+			return
+		}
+		if cli == nil {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status": "generic client not initialized",
+			})
+			return
+		}
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"status": "ok",
+		})
+	})
 }
 
 func setupSwaggerRoutes(h *server.Hertz) {
@@ -212,8 +309,22 @@ func setupSwaggerRoutes(h *server.Hertz) {
 	})
 }
 
+// setupProxyRoutes routes the methods under proxyPrefix to the generic client, e.g. /api/Method,
+// so that they don't collide with the routes of the Swagger UI.
 func setupProxyRoutes(h *server.Hertz, cli genericclient.Client) {
-	h.Any("/*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+	h.Any(proxyPrefix+"*ServiceMethod", func(c context.Context, ctx *app.RequestContext) {
+		if cli == nil {
+			handleError(ctx, "generic client not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		clientMu.RLock()
+		defer clientMu.RUnlock()
+		if clientClosed {
+			handleError(ctx, "generic client closed", http.StatusServiceUnavailable)
+			return
+		}
+
 		serviceMethod := ctx.Param("ServiceMethod")
 		if serviceMethod == "" {
 			handleError(ctx, "ServiceMethod not provided", http.StatusBadRequest)
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"github.com/hertz-contrib/swagger-generate/idl/protobuf/openapi"
+	"google.golang.org/protobuf/compiler/protogen"
+	"gopkg.in/yaml.v3"
+)
+
+// AsyncAPIDocument is a minimal AsyncAPI 2.x document describing the streaming methods.
+type AsyncAPIDocument struct {
+	AsyncAPI   string                      `yaml:"asyncapi"`
+	Info       AsyncAPIInfo                `yaml:"info"`
+	Channels   map[string]*AsyncAPIChannel `yaml:"channels"`
+	Components *AsyncAPIComponents         `yaml:"components,omitempty"`
+}
+
+type AsyncAPIInfo struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// AsyncAPIChannel holds the messages sent by the client (publish) and by the server (subscribe).
+type AsyncAPIChannel struct {
+	Description string             `yaml:"description,omitempty"`
+	Publish     *AsyncAPIOperation `yaml:"publish,omitempty"`
+	Subscribe   *AsyncAPIOperation `yaml:"subscribe,omitempty"`
+}
+
+type AsyncAPIOperation struct {
+	OperationID string           `yaml:"operationId"`
+	Tags        []*AsyncAPITag   `yaml:"tags,omitempty"`
+	Message     *AsyncAPIMessage `yaml:"message"`
+}
+
+type AsyncAPITag struct {
+	Name string `yaml:"name"`
+}
+
+type AsyncAPIMessage struct {
+	Name    string     `yaml:"name"`
+	Payload *yaml.Node `yaml:"payload"`
+}
+
+type AsyncAPIComponents struct {
+	Schemas *yaml.Node `yaml:"schemas,omitempty"`
+}
+
+// AsyncAPIGenerator generates an AsyncAPI document for the streaming methods of the services,
+// reusing the OpenAPI generator to build the message schemas.
+type AsyncAPIGenerator struct {
+	openapi *OpenAPIGenerator
+}
+
+// NewAsyncAPIGenerator creates a new AsyncAPI generator for a protoc plugin invocation.
+func NewAsyncAPIGenerator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) (*AsyncAPIGenerator, error) {
+	g, err := NewOpenAPIGenerator(plugin, conf, inputFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &AsyncAPIGenerator{openapi: g}, nil
+}
+
+// Run runs the generator.
+func (g *AsyncAPIGenerator) Run(outputFile *protogen.GeneratedFile) error {
+	d := g.buildDocument()
+	bytes, err := yaml.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to marshal yaml: %s", err.Error())
+	}
+	comment, err := g.openapi.comment()
+	if err != nil {
+		return fmt.Errorf("failed to read file header: %s", err.Error())
+	}
+	// The document is marshaled without nodes, so comment the lines of the banner and header by hand
+	var header strings.Builder
+	for _, line := range strings.Split(comment, "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			line = "# " + line
+		}
+		header.WriteString(line + "\n")
+	}
+	header.WriteString("\n")
+	if _, err = outputFile.Write(append([]byte(header.String()), bytes...)); err != nil {
+		return fmt.Errorf("failed to write yaml: %s", err.Error())
+	}
+	return nil
+}
+
+// buildDocument builds an AsyncAPI document with a channel for every streaming method, named after
+// the gRPC path of the method, so that the methods of different services don't share a channel.
+func (g *AsyncAPIGenerator) buildDocument() *AsyncAPIDocument {
+	conf := g.openapi.conf
+	d := &AsyncAPIDocument{
+		AsyncAPI: consts.AsyncAPIVersion,
+		Info: AsyncAPIInfo{
+			Title:       *conf.Title,
+			Version:     *conf.Version,
+			Description: *conf.Description,
+		},
+		Channels: map[string]*AsyncAPIChannel{},
+	}
+
+	var services []string
+	for _, file := range g.openapi.inputFiles {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			serviceName := string(service.Desc.Name())
+			for _, method := range service.Methods {
+				if !isStreamingMethod(method) {
+					continue
+				}
+				operationID := g.openapi.getOperationID(serviceName, string(method.Desc.Name()))
+				tags := []*AsyncAPITag{{Name: serviceName}}
+				d.Channels["/"+string(service.Desc.FullName())+"/"+string(method.Desc.Name())] = &AsyncAPIChannel{
+					Description: g.openapi.filterCommentString(method.Comments.Leading),
+					Publish: &AsyncAPIOperation{
+						OperationID: operationID + "_publish",
+						Tags:        tags,
+						Message:     g.messageForStream(method.Input),
+					},
+					Subscribe: &AsyncAPIOperation{
+						OperationID: operationID + "_subscribe",
+						Tags:        tags,
+						Message:     g.messageForStream(method.Output),
+					},
+				}
+				if len(services) == 0 || services[len(services)-1] != serviceName {
+					services = append(services, serviceName)
+				}
+			}
+		}
+	}
+
+	// If there is only 1 service, then use it's title for the document, if the document is missing it.
+	if d.Info.Title == "" && len(services) == 1 {
+		d.Info.Title = services[0] + " API"
+	}
+
+	// Generate the schemas referenced by the messages the same way as the OpenAPI document does.
+	doc := &openapi.Document{
+		Components: &openapi.Components{
+			Schemas: &openapi.SchemasOrReferences{
+				AdditionalProperties: []*openapi.NamedSchemaOrReference{},
+			},
+		},
+	}
+	for len(g.openapi.reflect.requiredSchemas) > 0 {
+		count := len(g.openapi.reflect.requiredSchemas)
+		for _, file := range g.openapi.plugin.Files {
+			g.openapi.addSchemasForMessagesToDocument(doc, file.Messages)
+		}
+		g.openapi.reflect.requiredSchemas = g.openapi.reflect.requiredSchemas[count:len(g.openapi.reflect.requiredSchemas)]
+	}
+	if pairs := doc.Components.Schemas.AdditionalProperties; len(pairs) > 0 {
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].Name < pairs[j].Name
+		})
+		d.Components = &AsyncAPIComponents{Schemas: doc.Components.Schemas.ToRawInfo()}
+	}
+	return d
+}
+
+// messageForStream returns the message carrying the payload of one direction of a stream.
+func (g *AsyncAPIGenerator) messageForStream(message *protogen.Message) *AsyncAPIMessage {
+	ref := &openapi.Reference{XRef: g.openapi.reflect.schemaReferenceForMessage(message.Desc)}
+	return &AsyncAPIMessage{
+		Name:    g.openapi.reflect.formatMessageName(message.Desc),
+		Payload: ref.ToRawInfo(),
+	}
+}
+
+// isStreamingMethod reports whether the client or the server of the method streams messages.
+func isStreamingMethod(method *protogen.Method) bool {
+	return method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer()
+}
@@ -38,6 +38,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
@@ -49,16 +50,26 @@ import (
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
 	any_pb "google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 type Configuration struct {
-	Version        *string
-	Title          *string
-	Description    *string
-	Naming         *string
-	FQSchemaNaming *bool
-	EnumType       *string
-	OutputMode     *string
+	Version             *string
+	Title               *string
+	Description         *string
+	Naming              *string
+	FQSchemaNaming      *bool
+	InlineSchemas       *bool
+	PruneUnused         *bool
+	ClosedSchemas       *bool
+	EnumType            *string
+	OutputMode          *string
+	FileHeader          *string
+	OperationIDTemplate *string
+	OpenAPIVersion      *string
+	Spec                *string
+	ProxyPrefix         *string
+	Validate            *bool
 }
 
 // In order to dynamically add google.rpc.Status responses we need
@@ -70,33 +81,155 @@ var (
 
 // OpenAPIGenerator holds internal state needed to generate an OpenAPIv3 document for a transcoded Protocol Buffer service.
 type OpenAPIGenerator struct {
-	conf              Configuration
-	plugin            *protogen.Plugin
-	inputFiles        []*protogen.File
-	reflect           *OpenAPIReflector
-	generatedSchemas  []string // Names of schemas that have already been generated.
-	linterRulePattern *regexp.Regexp
+	conf             Configuration
+	plugin           *protogen.Plugin
+	inputFiles       []*protogen.File
+	reflect          *OpenAPIReflector
+	generatedSchemas []string // Names of schemas that have already been generated.
+	// operationIDTemplate formats the operation IDs from the service and method names
+	operationIDTemplate *template.Template
 }
 
-// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation.
-func NewOpenAPIGenerator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) *OpenAPIGenerator {
+// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation. It fails if the
+// operation ID template can't be parsed.
+func NewOpenAPIGenerator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) (*OpenAPIGenerator, error) {
+	operationIDTemplate, err := common.NewOperationIDTemplate(*conf.OperationIDTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operation id template: %s", err.Error())
+	}
 	return &OpenAPIGenerator{
-		conf:              conf,
-		plugin:            plugin,
-		inputFiles:        inputFiles,
-		reflect:           NewOpenAPIReflector(conf),
-		generatedSchemas:  make([]string, 0),
-		linterRulePattern: regexp.MustCompile(`\(-- .* --\)`),
+		operationIDTemplate: operationIDTemplate,
+		conf:                conf,
+		plugin:              plugin,
+		inputFiles:          inputFiles,
+		reflect:             NewOpenAPIReflector(conf),
+		generatedSchemas:    make([]string, 0),
+	}, nil
+}
+
+// GenerateFromRequest builds the OpenAPI document of the files to generate in the code generator
+// request without running the plugin, so that tools and tests can call the generator from Go code.
+// The unset options of conf take the defaults of the plugin options. The document is not written
+// anywhere; use its YAMLValue method to get the bytes of openapi.yaml, and ConvertToOpenAPI31 of the
+// common utils for the OpenAPI version 3.1.0.
+func GenerateFromRequest(req *pluginpb.CodeGeneratorRequest, conf Configuration) (*openapi.Document, error) {
+	plugin, err := protogen.Options{}.New(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code generator request: %s", err.Error())
+	}
+	conf.setDefaults()
+	g, err := NewOpenAPIGenerator(plugin, conf, plugin.Files)
+	if err != nil {
+		return nil, err
 	}
+	return g.buildDocument(), nil
+}
+
+// setDefaults sets the unset options to the defaults of the plugin options.
+func (c *Configuration) setDefaults() {
+	if c.Version == nil {
+		c.Version = stringPtr("3.0.3")
+	}
+	if c.Title == nil {
+		c.Title = stringPtr("")
+	}
+	if c.Description == nil {
+		c.Description = stringPtr("")
+	}
+	if c.Naming == nil {
+		c.Naming = stringPtr("json")
+	}
+	if c.FQSchemaNaming == nil {
+		c.FQSchemaNaming = boolPtr(false)
+	}
+	if c.InlineSchemas == nil {
+		c.InlineSchemas = boolPtr(false)
+	}
+	if c.PruneUnused == nil {
+		c.PruneUnused = boolPtr(false)
+	}
+	if c.ClosedSchemas == nil {
+		c.ClosedSchemas = boolPtr(false)
+	}
+	if c.EnumType == nil {
+		c.EnumType = stringPtr("integer")
+	}
+	if c.OutputMode == nil {
+		c.OutputMode = stringPtr("merged")
+	}
+	if c.OperationIDTemplate == nil {
+		c.OperationIDTemplate = stringPtr(consts.DefaultOperationIDTemplate)
+	}
+	if c.OpenAPIVersion == nil {
+		c.OpenAPIVersion = stringPtr(consts.OpenAPIVersion)
+	}
+	if c.Spec == nil {
+		c.Spec = stringPtr(consts.SpecOpenAPI)
+	}
+	if c.ProxyPrefix == nil {
+		c.ProxyPrefix = stringPtr(consts.DefaultProxyPrefix)
+	}
+	if c.Validate == nil {
+		c.Validate = boolPtr(false)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// comment returns the head comment of the generated YAML files, the banner preceded by the file header.
+func (g *OpenAPIGenerator) comment() (string, error) {
+	banner := "Generated with " + consts.PluginNameProtocRpcSwagger + "\n" + consts.InfoURL + consts.PluginNameProtocRpcSwagger
+	var header string
+	if g.conf.FileHeader != nil {
+		var err error
+		if header, err = common.FileHeader(*g.conf.FileHeader); err != nil {
+			return "", err
+		}
+	}
+	if header == "" {
+		return banner, nil
+	}
+	return header + "\n\n" + banner, nil
 }
 
 // Run runs the generator.
 func (g *OpenAPIGenerator) Run(outputFile *protogen.GeneratedFile) error {
 	d := g.buildDocument()
-	bytes, err := d.YAMLValue("Generated with " + consts.PluginNameProtocRpcSwagger + "\n" + consts.InfoURL + consts.PluginNameProtocRpcSwagger)
+	comment, err := g.comment()
+	if err != nil {
+		return fmt.Errorf("failed to read file header: %s", err.Error())
+	}
+	bytes, err := d.YAMLValue(comment)
 	if err != nil {
 		return fmt.Errorf("failed to marshal yaml: %s", err.Error())
 	}
+	if *g.conf.PruneUnused {
+		if bytes, err = common.PruneSchemas(bytes); err != nil {
+			return fmt.Errorf("failed to prune schemas: %s", err.Error())
+		}
+	}
+	if *g.conf.InlineSchemas {
+		if bytes, err = common.InlineSchemas(bytes); err != nil {
+			return fmt.Errorf("failed to inline schemas: %s", err.Error())
+		}
+	}
+	if *g.conf.Validate {
+		if err = common.ValidateOpenAPI(bytes); err != nil {
+			return fmt.Errorf("failed to validate openapi document: %s", err.Error())
+		}
+	}
+	// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+	if *g.conf.OpenAPIVersion == consts.OpenAPIVersion31 {
+		if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+			return fmt.Errorf("failed to convert to openapi %s: %s", consts.OpenAPIVersion31, err.Error())
+		}
+	}
 	if _, err = outputFile.Write(bytes); err != nil {
 		return fmt.Errorf("failed to write yaml: %s", err.Error())
 	}
@@ -107,7 +240,7 @@ func (g *OpenAPIGenerator) Run(outputFile *protogen.GeneratedFile) error {
 func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	d := &openapi.Document{}
 
-	d.Openapi = consts.OpenAPIVersion
+	d.Openapi = *g.conf.OpenAPIVersion
 	d.Info = &openapi.Info{
 		Version:     *g.conf.Version,
 		Title:       *g.conf.Title,
@@ -124,6 +257,7 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	// Go through the files and add the services to the documents, keeping
 	// track of which schemas are referenced in the response so we can
 	// add them later.
+	var declaredTags []string
 	for _, file := range g.inputFiles {
 		if file.Generate {
 			// Merge any `Document` annotations with the current
@@ -131,6 +265,9 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 			if extDocument != nil {
 				if doc, ok := extDocument.(*openapi.Document); ok {
 					proto.Merge(d, doc)
+					for _, tag := range doc.GetTags() {
+						declaredTags = append(declaredTags, tag.Name)
+					}
 				} else {
 					logs.Errorf("unexpected type for Document: %T", extDocument)
 				}
@@ -161,6 +298,8 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 		d.Tags[0].Description = ""
 	}
 
+	addOperationTagsToDocument(d)
+
 	var allServers []string
 
 	// If paths methods has servers, but they're all the same, then move servers to path level
@@ -202,13 +341,7 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	}
 
 	// Sort the tags.
-	{
-		pairs := d.Tags
-		sort.Slice(pairs, func(i, j int) bool {
-			return pairs[i].Name < pairs[j].Name
-		})
-		d.Tags = pairs
-	}
+	sortTags(d.Tags, declaredTags)
 	// Sort the paths.
 	{
 		pairs := d.Paths.Path
@@ -228,9 +361,19 @@ func (g *OpenAPIGenerator) buildDocument() *openapi.Document {
 	return d
 }
 
+// getOperationID returns the operation ID of the method of the service formatted by the operation ID template.
+func (g *OpenAPIGenerator) getOperationID(service, method string) string {
+	operationID, err := common.FormatOperationID(g.operationIDTemplate, service, method)
+	if err != nil {
+		logs.Errorf("Error formatting operation id of '%s': %s", method, err)
+		return service + "_" + method
+	}
+	return operationID
+}
+
 // filterCommentString removes linter rules from comments.
 func (g *OpenAPIGenerator) filterCommentString(c protogen.Comments) string {
-	comment := g.linterRulePattern.ReplaceAllString(string(c), "")
+	comment := linterRulePattern.ReplaceAllString(string(c), "")
 	return strings.TrimSpace(comment)
 }
 
@@ -326,6 +469,7 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputMessage *protogen.Message) *op
 	if extSchema != nil {
 		proto.Merge(schema, extSchema.(*openapi.Schema))
 	}
+	g.closeSchema(schema)
 
 	schema.Required = required
 	return schema
@@ -401,6 +545,7 @@ func (g *OpenAPIGenerator) buildOperation(
 						Content: &openapi.MediaTypes{
 							AdditionalProperties: additionalProperties,
 						},
+						Required: isRequestBodyRequired(bodySchema),
 					},
 				},
 			}
@@ -516,11 +661,15 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*p
 		annotationsCount := 0
 
 		for _, method := range service.Methods {
+			// Streaming methods are described by the AsyncAPI document instead
+			if *g.conf.Spec == consts.SpecAsyncAPI && isStreamingMethod(method) {
+				continue
+			}
 			comment := g.filterCommentString(method.Comments.Leading)
 			inputMessage := method.Input
 			outputMessage := method.Output
-			operationID := string(service.Desc.Name()) + "_" + string(method.Desc.Name())
-			path := "/" + string(method.Desc.Name())
+			operationID := g.getOperationID(string(service.Desc.Name()), string(method.Desc.Name()))
+			path := common.ProxyPrefix(*g.conf.ProxyPrefix) + string(method.Desc.Name())
 
 			annotationsCount++
 			var host string
@@ -535,16 +684,73 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*p
 
 			if extOperation != nil {
 				proto.Merge(op, extOperation.(*openapi.Operation))
+				mergeOperationContent(op)
 			}
 			g.addOperationToDocument(d, op, path2)
 		}
 		if annotationsCount > 0 {
 			comment := g.filterCommentString(service.Comments.Leading)
-			d.Tags = append(d.Tags, &openapi.Tag{Name: string(service.Desc.Name()), Description: comment})
+			addTagToDocument(d, &openapi.Tag{Name: string(service.Desc.Name()), Description: comment})
+		}
+	}
+}
+
+// addTagToDocument adds the tag of a service to the document, a tag with the same name declared
+// in the openapi.document annotation is kept and only takes the description if it has none.
+func addTagToDocument(d *openapi.Document, tag *openapi.Tag) {
+	for _, t := range d.Tags {
+		if t.Name == tag.Name {
+			if t.Description == "" {
+				t.Description = tag.Description
+			}
+			return
+		}
+	}
+	d.Tags = append(d.Tags, tag)
+}
+
+// addOperationTagsToDocument adds the tags the operations carry besides the tags of their services, e.g.
+// admin or beta, to the document, so that every tag referenced by an operation is listed. The tags of
+// openapi.operation are appended to the tag of the service when merged, so the duplicates are dropped.
+func addOperationTagsToDocument(d *openapi.Document) {
+	for _, path := range d.Paths.Path {
+		op := path.Value.Post
+		if op == nil {
+			continue
+		}
+		var tags []string
+		for _, tag := range op.Tags {
+			tags = common.AppendUnique(tags, tag)
+		}
+		op.Tags = tags
+		for _, tag := range op.Tags {
+			addTagToDocument(d, &openapi.Tag{Name: tag})
 		}
 	}
 }
 
+// sortTags sorts the tags declared in the openapi.document annotation first, in their declared
+// order, followed by the other tags in alphabetical order.
+func sortTags(tags []*openapi.Tag, declared []string) {
+	order := make(map[string]int, len(declared))
+	for i, name := range declared {
+		if _, ok := order[name]; !ok {
+			order[name] = i
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		oi, iok := order[tags[i].Name]
+		oj, jok := order[tags[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		if iok || jok {
+			return iok
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
 // addSchemaToDocument adds the schema to the document if required
 func (g *OpenAPIGenerator) addSchemaToDocument(d *openapi.Document, schema *openapi.NamedSchemaOrReference) {
 	if common.Contains(g.generatedSchemas, schema.Name) {
@@ -554,6 +760,16 @@ func (g *OpenAPIGenerator) addSchemaToDocument(d *openapi.Document, schema *open
 	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, schema)
 }
 
+// closeSchema disallows the properties not declared by the object schema when closed_schemas
+// is set, unless the openapi.schema annotation already sets additionalProperties.
+func (g *OpenAPIGenerator) closeSchema(schema *openapi.Schema) {
+	if *g.conf.ClosedSchemas && schema.AdditionalProperties == nil {
+		schema.AdditionalProperties = &openapi.AdditionalPropertiesItem{
+			Oneof: &openapi.AdditionalPropertiesItem_Boolean{Boolean: false},
+		}
+	}
+}
+
 // addSchemasForMessagesToDocument adds info from one file descriptor.
 func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document, messages []*protogen.Message) {
 	// For each message, generate a definition.
@@ -670,6 +886,7 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 		if extSchema != nil {
 			proto.Merge(schema, extSchema.(*openapi.Schema))
 		}
+		g.closeSchema(schema)
 
 		// Add the schema to the components.schema list.
 		g.addSchemaToDocument(d, &openapi.NamedSchemaOrReference{
@@ -682,3 +899,75 @@ func (g *OpenAPIGenerator) addSchemasForMessagesToDocument(d *openapi.Document,
 		})
 	}
 }
+
+// isRequestBodyRequired reports whether a field bound to the request body is required,
+// that is the `required` of one of the body schemas isn't empty.
+func isRequestBodyRequired(schemas ...*openapi.Schema) bool {
+	for _, schema := range schemas {
+		if schema != nil && len(schema.Required) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOperationContent merges the media types and responses declared again by an `Operation`
+// annotation into the generated ones, so that e.g. examples can be attached to them.
+func mergeOperationContent(op *openapi.Operation) {
+	if requestBody := op.GetRequestBody().GetRequestBody(); requestBody != nil {
+		mergeMediaTypes(requestBody.Content)
+	}
+	if op.Responses == nil {
+		return
+	}
+	var responses []*openapi.NamedResponseOrReference
+	for _, response := range op.Responses.ResponseOrReference {
+		merged := false
+		for _, r := range responses {
+			if r.Name == response.Name {
+				if r.Value == nil {
+					r.Value = response.Value
+				} else if response.Value != nil {
+					proto.Merge(r.Value, response.Value)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			responses = append(responses, response)
+		}
+	}
+	op.Responses.ResponseOrReference = responses
+	for _, response := range responses {
+		if r := response.GetValue().GetResponse(); r != nil {
+			mergeMediaTypes(r.Content)
+		}
+	}
+}
+
+// mergeMediaTypes merges the media types with the same name into the first of them.
+func mergeMediaTypes(content *openapi.MediaTypes) {
+	if content == nil {
+		return
+	}
+	var mediaTypes []*openapi.NamedMediaType
+	for _, mediaType := range content.AdditionalProperties {
+		merged := false
+		for _, m := range mediaTypes {
+			if m.Name == mediaType.Name {
+				if m.Value == nil {
+					m.Value = mediaType.Value
+				} else if mediaType.Value != nil {
+					proto.Merge(m.Value, mediaType.Value)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	content.AdditionalProperties = mediaTypes
+}
@@ -35,6 +35,8 @@ package generator
 
 import (
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hertz-contrib/swagger-generate/common/consts"
@@ -45,6 +47,9 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// linterRulePattern matches the linter rules in the comments, e.g. `(-- api-linter: ... --)`.
+var linterRulePattern = regexp.MustCompile(consts.LinterRulePatternRegexp)
+
 type OpenAPIReflector struct {
 	conf            Configuration
 	requiredSchemas []string // Names of schemas which are used through references.
@@ -58,6 +63,32 @@ func NewOpenAPIReflector(conf Configuration) *OpenAPIReflector {
 	}
 }
 
+// enumDescriptions returns the x-enumDescriptions extension listing the comments of the values of the enum
+// in the order of its enum, so that Swagger UI and the other consumers show the meaning of each value. An
+// enum whose values have no comments gets none.
+func (r *OpenAPIReflector) enumDescriptions(enum protoreflect.EnumDescriptor) []*openapi.NamedAny {
+	locations := enum.ParentFile().SourceLocations()
+	descriptions := make([]string, 0, enum.Values().Len())
+	commented := false
+	for i := 0; i < enum.Values().Len(); i++ {
+		location := locations.ByDescriptor(enum.Values().Get(i))
+		comment := location.LeadingComments
+		if strings.TrimSpace(comment) == "" {
+			comment = location.TrailingComments
+		}
+		description := strings.TrimSpace(linterRulePattern.ReplaceAllString(comment, ""))
+		commented = commented || description != ""
+		descriptions = append(descriptions, strconv.Quote(description))
+	}
+	if !commented {
+		return nil
+	}
+	return []*openapi.NamedAny{{
+		Name:  consts.ExtensionEnumDescriptions,
+		Value: &openapi.Any{Yaml: "[" + strings.Join(descriptions, ", ") + "]"},
+	}}
+}
+
 func (r *OpenAPIReflector) getMessageName(message protoreflect.MessageDescriptor) string {
 	prefix := ""
 	parent := message.Parent()
@@ -148,6 +179,9 @@ func (r *OpenAPIReflector) schemaOrReferenceForMessage(message protoreflect.Mess
 	case ".google.protobuf.Struct":
 		return wk.NewGoogleProtobufStructSchema()
 
+	case ".google.protobuf.ListValue":
+		return wk.NewGoogleProtobufListValueSchema(r.schemaOrReferenceForMessage(message.Fields().ByName("values").Message()))
+
 	case ".google.protobuf.Empty":
 		// Empty is closer to JSON undefined than null, so ignore this field
 		return nil //&v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Schema{Schema: &v3.Schema{Type: "null"}}}
@@ -216,7 +250,14 @@ func (r *OpenAPIReflector) schemaOrReferenceForField(field protoreflect.FieldDes
 		kindSchema = wk.NewStringSchema()
 
 	case protoreflect.EnumKind:
+		if field.Enum().FullName() == "google.protobuf.NullValue" {
+			kindSchema = wk.NewGoogleProtobufNullValueSchema()
+			break
+		}
 		kindSchema = wk.NewEnumSchema(*&r.conf.EnumType, field)
+		if kindSchema.GetSchema().GetType() == "string" {
+			kindSchema.GetSchema().SpecificationExtension = r.enumDescriptions(field.Enum())
+		}
 
 	case protoreflect.BoolKind:
 		kindSchema = wk.NewBooleanSchema()
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
 	"github.com/hertz-contrib/swagger-generate/common/tpl"
 	"github.com/hertz-contrib/swagger-generate/common/utils"
@@ -33,12 +34,30 @@ import (
 )
 
 type ServerConfiguration struct {
-	KitexAddr *string
+	KitexAddr     *string
+	ProxyPrefix   *string
+	RPCTimeout    *string
+	MaxRetryTimes *int
+	Registry      *string
+	RegistryAddr  *string
+	ServiceName   *string
+	HertzAddr     *string
+	TLSCertFile   *string
+	TLSKeyFile    *string
 }
 
 type ServerGenerator struct {
-	IdlPath   string
-	KitexAddr string
+	IdlPath       string
+	KitexAddr     string
+	ProxyPrefix   string
+	RPCTimeout    int64
+	MaxRetryTimes int
+	Registry      string
+	RegistryAddr  string
+	ServiceName   string
+	HertzAddr     string
+	TLSCertFile   string
+	TLSKeyFile    string
 }
 
 func NewServerGenerator(conf ServerConfiguration, inputFiles []*protogen.File) (*ServerGenerator, error) {
@@ -66,9 +85,82 @@ func NewServerGenerator(conf ServerConfiguration, inputFiles []*protogen.File) (
 		return nil, fmt.Errorf("invalid Kitex address: %w", err)
 	}
 
+	var proxyPrefix string
+	if conf.ProxyPrefix != nil {
+		proxyPrefix = *conf.ProxyPrefix
+	}
+	proxyPrefix = utils.ProxyPrefix(proxyPrefix)
+
+	var rpcTimeoutStr string
+	if conf.RPCTimeout != nil {
+		rpcTimeoutStr = *conf.RPCTimeout
+	}
+	rpcTimeout, err := utils.RPCTimeoutMillis(rpcTimeoutStr)
+	if err != nil {
+		return nil, err
+	}
+	var maxRetryTimes int
+	if conf.MaxRetryTimes != nil {
+		maxRetryTimes = *conf.MaxRetryTimes
+	}
+	if err := utils.ValidateRetryTimes(maxRetryTimes); err != nil {
+		return nil, err
+	}
+
+	var registry, registryAddr, serviceName string
+	if conf.Registry != nil && *conf.Registry != "" {
+		registry = *conf.Registry
+		if conf.RegistryAddr != nil {
+			registryAddr = *conf.RegistryAddr
+		}
+		registryAddr, err = utils.RegistryAddr(registry, registryAddr)
+		if err != nil {
+			return nil, err
+		}
+		// Kitex calls the last service of the proto file by default
+		if conf.ServiceName != nil {
+			serviceName = *conf.ServiceName
+		}
+		if services := genFiles[0].Services; serviceName == "" && len(services) > 0 {
+			serviceName = string(services[len(services)-1].Desc.Name())
+		}
+		if serviceName == "" {
+			return nil, errors.New("service_name must be set to resolve the service with a registry")
+		}
+	}
+
+	var hertzAddr, tlsCertFile, tlsKeyFile string
+	if conf.TLSCertFile != nil {
+		tlsCertFile = *conf.TLSCertFile
+	}
+	if conf.TLSKeyFile != nil {
+		tlsKeyFile = *conf.TLSKeyFile
+	}
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		return nil, errors.New("tls_cert_file and tls_key_file must be set together")
+	}
+	if tlsCertFile != "" {
+		hertzAddr = consts.DefaultHertzAddr
+		if conf.HertzAddr != nil && *conf.HertzAddr != "" {
+			hertzAddr = *conf.HertzAddr
+		}
+		if err := validateAddress(hertzAddr); err != nil {
+			return nil, fmt.Errorf("invalid Hertz address: %w", err)
+		}
+	}
+
 	return &ServerGenerator{
-		IdlPath:   idlPath,
-		KitexAddr: *kitexAddr,
+		IdlPath:       idlPath,
+		KitexAddr:     *kitexAddr,
+		ProxyPrefix:   proxyPrefix,
+		RPCTimeout:    rpcTimeout,
+		MaxRetryTimes: maxRetryTimes,
+		Registry:      registry,
+		RegistryAddr:  registryAddr,
+		ServiceName:   serviceName,
+		HertzAddr:     hertzAddr,
+		TLSCertFile:   tlsCertFile,
+		TLSKeyFile:    tlsKeyFile,
 	}, nil
 }
 
@@ -85,9 +177,9 @@ func validateAddress(addr string) error {
 func (g *ServerGenerator) Generate(outputFile *protogen.GeneratedFile) error {
 	filePath := filepath.Join(filepath.Dir(g.IdlPath), consts.DefaultOutputSwaggerFile)
 	if utils.FileExists(filePath) {
-		updatedContent, err := updateVariables(filePath, g.KitexAddr, g.IdlPath)
+		updatedContent, err := g.updateVariables(filePath)
 		if err != nil {
-			return errors.New("failed to update variables in the existing file")
+			return fmt.Errorf("failed to update variables in the existing file: %s", err.Error())
 		}
 		if _, err = outputFile.Write([]byte(updatedContent)); err != nil {
 			return errors.New("failed to write output file")
@@ -111,7 +203,7 @@ func (g *ServerGenerator) Generate(outputFile *protogen.GeneratedFile) error {
 	return nil
 }
 
-func updateVariables(filePath, newKitexAddr, newIdlPath string) (string, error) {
+func (g *ServerGenerator) updateVariables(filePath string) (string, error) {
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %v", err)
@@ -120,8 +212,37 @@ func updateVariables(filePath, newKitexAddr, newIdlPath string) (string, error)
 	kitexAddrPattern := regexp.MustCompile(`kitexAddr\s*=\s*"(.*?)"`)
 	idlPathPattern := regexp.MustCompile(`idlFile\s*=\s*"(.*?)"`)
 
-	updatedContent := kitexAddrPattern.ReplaceAllString(string(content), fmt.Sprintf(`kitexAddr = "%s"`, newKitexAddr))
-	updatedContent = idlPathPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`idlFile = "%s"`, newIdlPath))
+	updatedContent := kitexAddrPattern.ReplaceAllString(string(content), fmt.Sprintf(`kitexAddr = "%s"`, g.KitexAddr))
+	updatedContent = idlPathPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`idlFile = "%s"`, g.IdlPath))
+
+	var ok bool
+	if updatedContent, ok = utils.UpdateProxyPrefix(updatedContent, g.ProxyPrefix); !ok {
+		return "", fmt.Errorf("%s does not route the methods with the proxy prefix, remove it to generate it again", filePath)
+	}
+
+	// A swagger.go generated before the client policy calls without timeout and retries
+	rpcTimeoutPattern := regexp.MustCompile(`rpcTimeout\s*=\s*\d+ \* time\.Millisecond`)
+	maxRetryTimesPattern := regexp.MustCompile(`maxRetryTimes\s*=\s*\d+`)
+	if rpcTimeoutPattern.MatchString(updatedContent) && maxRetryTimesPattern.MatchString(updatedContent) {
+		updatedContent = rpcTimeoutPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`rpcTimeout    = %d * time.Millisecond`, g.RPCTimeout))
+		updatedContent = maxRetryTimesPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`maxRetryTimes = %d`, g.MaxRetryTimes))
+	} else {
+		logs.Warnf("%s calls without timeout and retries, remove it to generate it again", filePath)
+	}
+
+	// The registry is only wired into a newly generated swagger.go
+	var registry string
+	if m := regexp.MustCompile(`"github\.com/kitex-contrib/registry-(\w+)"`).FindStringSubmatch(updatedContent); m != nil {
+		registry = m[1]
+	}
+	if registry != g.Registry {
+		logs.Warnf("%s does not resolve the service as configured, remove it to generate it again", filePath)
+	} else if registry != "" {
+		registryAddrPattern := regexp.MustCompile(`registryAddr\s*=\s*"(.*?)"`)
+		serviceNamePattern := regexp.MustCompile(`serviceName\s*=\s*"(.*?)"`)
+		updatedContent = registryAddrPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`registryAddr = "%s"`, g.RegistryAddr))
+		updatedContent = serviceNamePattern.ReplaceAllString(updatedContent, fmt.Sprintf(`serviceName  = "%s"`, g.ServiceName))
+	}
 
 	return updatedContent, nil
 }
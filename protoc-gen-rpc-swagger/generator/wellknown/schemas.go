@@ -208,6 +208,24 @@ func NewGoogleProtobufStructSchema() *v3.SchemaOrReference {
 	}
 }
 
+// google.protobuf.ListValue is equivalent to a JSON array of google.protobuf.Value
+func NewGoogleProtobufListValueSchema(value_schema *v3.SchemaOrReference) *v3.SchemaOrReference {
+	return NewListSchema(value_schema)
+}
+
+// google.protobuf.NullValue is serialized as the JSON null, the only value of a nullable object
+func NewGoogleProtobufNullValueSchema() *v3.SchemaOrReference {
+	return &v3.SchemaOrReference{
+		Oneof: &v3.SchemaOrReference_Schema{
+			Schema: &v3.Schema{
+				Type:     "object",
+				Nullable: true,
+				Enum:     []*v3.Any{{Yaml: "null"}},
+			},
+		},
+	}
+}
+
 // google.protobuf.Value is handled specially
 // See here for the details on the JSON mapping:
 //
@@ -35,8 +35,12 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/hertz-contrib/swagger-generate/common/consts"
 	"github.com/hertz-contrib/swagger-generate/protoc-gen-rpc-swagger/generator"
@@ -47,45 +51,76 @@ import (
 var flags flag.FlagSet
 
 func main() {
+	proxyPrefix := flags.String("proxy_prefix", consts.DefaultProxyPrefix, `path prefix under which the proxy in swagger.go routes the methods, e.g. "/api/". Use "/" to route them at the root`)
+	concurrency := flags.Int("concurrency", 0, `max number of files generated at once in the "source_relative" and "both" output modes, defaults to the number of CPUs`)
 	conf := generator.Configuration{
-		Version:        flags.String("version", "3.0.3", "version number text, e.g. 1.2.3"),
-		Title:          flags.String("title", "", "name of the API"),
-		Description:    flags.String("description", "", "description of the API"),
-		Naming:         flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
-		FQSchemaNaming: flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
-		EnumType:       flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
-		OutputMode:     flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative' to generate a separate '[inputfile].openapi.yaml' next to each '[inputfile].proto'.`),
+		Version:             flags.String("version", "3.0.3", "version number text, e.g. 1.2.3"),
+		Title:               flags.String("title", "", "name of the API"),
+		Description:         flags.String("description", "", "description of the API"),
+		Naming:              flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
+		FQSchemaNaming:      flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
+		InlineSchemas:       flags.Bool("inline_schemas", false, `inline the schemas referenced only once at the place of the reference instead of in components`),
+		PruneUnused:         flags.Bool("prune_unused", false, `remove the component schemas that are not referenced by the paths or the other components`),
+		ClosedSchemas:       flags.Bool("closed_schemas", false, `set additionalProperties to false on the object schemas of the messages to reject the undeclared properties`),
+		EnumType:            flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
+		OutputMode:          flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative" to generate a separate "[inputfile].openapi.yaml" next to each "[inputfile].proto", or "both" to generate them along with the single openapi.yaml.`),
+		FileHeader:          flags.String("file_header", "", "file whose content is prepended as comments to the generated YAML files, e.g. a license header"),
+		OperationIDTemplate: flags.String("operation_id_template", consts.DefaultOperationIDTemplate, `Go template of the operation IDs, using .Service and .Method and the lowerFirst and upperFirst functions, e.g. "{{lowerFirst .Method}}"`),
+		OpenAPIVersion:      flags.String("openapi_version", consts.OpenAPIVersion, `version of the OpenAPI specification of the document. Use "3.1.0" to generate an OpenAPI 3.1 document`),
+		Spec:                flags.String("spec", consts.SpecOpenAPI, `specification of the streaming methods. Use "asyncapi" to describe them in an experimental asyncapi.yaml instead of openapi.yaml`),
+		ProxyPrefix:         proxyPrefix,
+		Validate:            flags.Bool("validate", false, `validate the generated document against the OpenAPI 3 specification and fail if it is invalid`),
 	}
 
 	serverConf := generator.ServerConfiguration{
-		KitexAddr: flags.String("kitex_addr", "127.0.0.1:8888", "kitex server address"),
+		KitexAddr:     flags.String("kitex_addr", "127.0.0.1:8888", "kitex server address"),
+		ProxyPrefix:   proxyPrefix,
+		RPCTimeout:    flags.String("rpc_timeout", consts.DefaultRPCTimeout, `timeout of the calls of the proxy to the kitex server, e.g. "500ms". Use "0" to disable it`),
+		MaxRetryTimes: flags.Int("max_retry_times", 0, "max times the proxy retries the failed calls to the kitex server, up to 5. Use 0 to disable the retries"),
+		Registry:      flags.String("registry", "", `registry the proxy resolves the kitex server with instead of kitex_addr, "etcd" or "consul"`),
+		RegistryAddr:  flags.String("registry_addr", "", "address of the registry, defaults to 127.0.0.1:2379 for etcd and 127.0.0.1:8500 for consul"),
+		ServiceName:   flags.String("service_name", "", "name of the kitex server in the registry, defaults to the last service of the proto file"),
+		HertzAddr:     flags.String("hertz_addr", "", "address of the HTTPS server serving the Swagger UI, defaults to 127.0.0.1:8443 when TLS is enabled"),
+		TLSCertFile:   flags.String("tls_cert_file", "", "TLS certificate file, enables serving the Swagger UI over HTTPS"),
+		TLSKeyFile:    flags.String("tls_key_file", "", "TLS key file, enables serving the Swagger UI over HTTPS"),
 	}
 
 	opts := protogen.Options{
 		ParamFunc: flags.Set,
 	}
 
-	opts.Run(func(plugin *protogen.Plugin) error {
+	generate := func(plugin *protogen.Plugin) error {
 		// Enable "optional" keyword in front of type (e.g. optional string label = 1;)
 		plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		if *conf.OutputMode == "source_relative" {
-			for _, file := range plugin.Files {
-				if !file.Generate {
-					continue
-				}
-				outfileName := strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())) + "." + consts.DefaultOutputYamlFile
-				outputFile := plugin.NewGeneratedFile(outfileName, "")
-				gen := generator.NewOpenAPIGenerator(plugin, conf, []*protogen.File{file})
-				if err := gen.Run(outputFile); err != nil {
-					return err
-				}
+		if *conf.Spec != consts.SpecOpenAPI && *conf.Spec != consts.SpecAsyncAPI {
+			return fmt.Errorf("unsupported spec %q, use %q or %q", *conf.Spec, consts.SpecOpenAPI, consts.SpecAsyncAPI)
+		}
+		if *conf.OpenAPIVersion != consts.OpenAPIVersion && *conf.OpenAPIVersion != consts.OpenAPIVersion31 {
+			return fmt.Errorf("unsupported openapi version %q, use %q or %q", *conf.OpenAPIVersion, consts.OpenAPIVersion, consts.OpenAPIVersion31)
+		}
+		if *conf.OutputMode == consts.OutputModeSourceRelative || *conf.OutputMode == consts.OutputModeBoth {
+			if err := generateFiles(plugin, conf, *concurrency); err != nil {
+				return err
 			}
-		} else {
+		}
+		if *conf.OutputMode != consts.OutputModeSourceRelative {
 			outputFile := plugin.NewGeneratedFile(consts.DefaultOutputYamlFile, "")
-			gen := generator.NewOpenAPIGenerator(plugin, conf, plugin.Files)
-			if err := gen.Run(outputFile); err != nil {
+			gen, err := generator.NewOpenAPIGenerator(plugin, conf, plugin.Files)
+			if err != nil {
+				return err
+			}
+			if err = gen.Run(outputFile); err != nil {
 				return err
 			}
+			if *conf.Spec == consts.SpecAsyncAPI {
+				asyncGen, err := generator.NewAsyncAPIGenerator(plugin, conf, plugin.Files)
+				if err != nil {
+					return err
+				}
+				if err = asyncGen.Run(plugin.NewGeneratedFile(consts.DefaultOutputAsyncAPIFile, "")); err != nil {
+					return err
+				}
+			}
 		}
 		outputFile := plugin.NewGeneratedFile(consts.DefaultOutputSwaggerFile, "")
 		gen, err := generator.NewServerGenerator(serverConf, plugin.Files)
@@ -96,5 +131,81 @@ func main() {
 			return err
 		}
 		return nil
-	})
+	}
+
+	// protoc runs the plugin without arguments, the arguments select the descriptor set input
+	if len(os.Args) > 1 {
+		if err := runDescriptorSet(opts, generate, os.Args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filepath.Base(os.Args[0]), err)
+			os.Exit(1)
+		}
+		return
+	}
+	opts.Run(generate)
+}
+
+// generateFiles generates the documents of each file to generate next to it. The generation of a file
+// is independent of the others, so the files are generated by up to concurrency workers at once. The
+// output files are registered with the plugin beforehand, which isn't safe for concurrent use.
+func generateFiles(plugin *protogen.Plugin, conf generator.Configuration, concurrency int) error {
+	type job struct {
+		file        *protogen.File
+		output      *protogen.GeneratedFile
+		asyncOutput *protogen.GeneratedFile
+	}
+	var jobs []job
+	for _, file := range plugin.Files {
+		if !file.Generate {
+			continue
+		}
+		prefix := strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())) + "."
+		j := job{file: file, output: plugin.NewGeneratedFile(prefix+consts.DefaultOutputYamlFile, "")}
+		if *conf.Spec == consts.SpecAsyncAPI {
+			j.asyncOutput = plugin.NewGeneratedFile(prefix+consts.DefaultOutputAsyncAPIFile, "")
+		}
+		jobs = append(jobs, j)
+	}
+
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	errs := make([]error, len(jobs))
+	workers := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, j job) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			files := []*protogen.File{j.file}
+			gen, err := generator.NewOpenAPIGenerator(plugin, conf, files)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if errs[i] = gen.Run(j.output); errs[i] != nil {
+				return
+			}
+			if j.asyncOutput != nil {
+				asyncGen, err := generator.NewAsyncAPIGenerator(plugin, conf, files)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				errs[i] = asyncGen.Run(j.asyncOutput)
+			}
+		}(i, j)
+	}
+	wg.Wait()
+
+	// The error of the first failing file is reported, as with a sequential generation
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -23,7 +23,27 @@ import (
 )
 
 type Arguments struct {
-	OutputDir string
+	OutputDir            string
+	OutputMode           string
+	FileHeader           string
+	DeprecatedAnnotation string
+	EnumType             string
+	FQSchemaNaming       bool
+	InlineSchemas        bool
+	PruneUnused          bool
+	ClosedSchemas        bool
+	OperationIDTemplate  string
+	OpenAPIVersion       string
+	BodySchemaSuffix     string
+	FormSchemaSuffix     string
+	RawBodySchemaSuffix  string
+	ReuseParameters      bool
+	Validate             bool
+	PreserveMarkdown     bool
+	SynthesizeExamples   bool
+	BasePath             string
+	DescriptionFromFile  bool
+	Swagger2             bool
 }
 
 func (a *Arguments) Unpack(args []string) error {
@@ -34,16 +34,23 @@
 package generator
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
 	"github.com/cloudwego/thriftgo/thrift_reflection"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"github.com/hertz-contrib/swagger-generate/common/thriftutils"
 	common "github.com/hertz-contrib/swagger-generate/common/utils"
 	openapi "github.com/hertz-contrib/swagger-generate/idl/thrift"
 	"github.com/hertz-contrib/swagger-generate/thrift-gen-http-swagger/args"
@@ -53,29 +60,99 @@ import (
 type OpenAPIGenerator struct {
 	fileDesc         *thrift_reflection.FileDescriptor
 	ast              *parser.Thrift
+	args             *args.Arguments
 	generatedSchemas []string
 	requiredSchemas  []string
 	requiredTypeDesc []*thrift_reflection.StructDescriptor
+	// schemaNames are the component schema names of the structs, keyed by their file path and name
+	schemaNames map[string]string
+	// schemaOrigins names the type each schema name was generated from, to report colliding names
+	schemaOrigins map[string]string
+	// schemaNameErr is the first collision of schema names, which fails the document
+	schemaNameErr error
+	// bodySchemaSuffix, formSchemaSuffix and rawBodySchemaSuffix are appended to the struct schema
+	// names to name the body, form and raw body schemas
+	bodySchemaSuffix    string
+	formSchemaSuffix    string
+	rawBodySchemaSuffix string
+	// walkingStructs are the structs whose nested structs are being added, to stop at recursive structs
+	walkingStructs []string
+	// walkedStructs holds the number of required structs when each struct was last walked without adding
+	// a schema, walking it again is a no-op until more structs are required
+	walkedStructs map[string]int
+	// skippedDepth is the lowest depth in walkingStructs of the recursive structs skipped by the current walk
+	skippedDepth int
+	// operationIDTemplate formats the operation IDs from the service and function names
+	operationIDTemplate *template.Template
+	// service restricts the document to the service of that name, all the services are documented if empty
+	service string
 }
 
-// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation.
-func NewOpenAPIGenerator(ast *parser.Thrift) *OpenAPIGenerator {
+// NewOpenAPIGenerator creates a new generator for a thriftgo plugin invocation. It fails if the
+// operation ID template can't be parsed.
+func NewOpenAPIGenerator(ast *parser.Thrift, args *args.Arguments) (*OpenAPIGenerator, error) {
 	_, fileDesc := thrift_reflection.RegisterAST(ast)
+	utils.RegisterExceptionFields(fileDesc)
+	operationIDTemplate, err := common.NewOperationIDTemplate(args.OperationIDTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operation id template: %s", err.Error())
+	}
+	schemaNames, err := newSchemaNames(ast, fileDesc, args.FQSchemaNaming)
+	if err != nil {
+		return nil, err
+	}
 	return &OpenAPIGenerator{
-		fileDesc:         fileDesc,
-		ast:              ast,
-		generatedSchemas: make([]string, 0),
+		fileDesc:            fileDesc,
+		ast:                 ast,
+		args:                args,
+		generatedSchemas:    make([]string, 0),
+		schemaNames:         schemaNames,
+		walkedStructs:       make(map[string]int),
+		schemaOrigins:       make(map[string]string),
+		bodySchemaSuffix:    withDefault(args.BodySchemaSuffix, consts.ComponentSchemaSuffixBody),
+		formSchemaSuffix:    withDefault(args.FormSchemaSuffix, consts.ComponentSchemaSuffixForm),
+		rawBodySchemaSuffix: withDefault(args.RawBodySchemaSuffix, consts.ComponentSchemaSuffixRawBody),
+		operationIDTemplate: operationIDTemplate,
+	}, nil
+}
+
+// GenerateFromThriftAST builds the OpenAPI document of the services in the thrift AST without running
+// the plugin, so that tools and tests can call the generator from Go code. Nil arguments use the
+// defaults of the plugin options. The document is not written anywhere; use its YAMLValue method to
+// get the bytes of openapi.yaml, and ConvertToOpenAPI31 or ConvertToSwagger2 of the common utils for
+// OpenAPIVersion 3.1.0 or Swagger2.
+func GenerateFromThriftAST(ast *parser.Thrift, arguments *args.Arguments) (*openapi.Document, error) {
+	if arguments == nil {
+		arguments = &args.Arguments{}
+	}
+	g, err := NewOpenAPIGenerator(ast, arguments)
+	if err != nil {
+		return nil, err
 	}
+	return g.buildDocument()
 }
 
-func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Generated {
+// buildDocument builds an OpenAPIv3 document from the thrift AST.
+func (g *OpenAPIGenerator) buildDocument() (*openapi.Document, error) {
 	d := &openapi.Document{}
 
 	version := consts.OpenAPIVersion
+	if g.args.OpenAPIVersion != "" {
+		version = g.args.OpenAPIVersion
+	}
+	if version != consts.OpenAPIVersion && version != consts.OpenAPIVersion31 {
+		return nil, fmt.Errorf("unsupported openapi version %q, use %q or %q", version, consts.OpenAPIVersion, consts.OpenAPIVersion31)
+	}
+	if g.args.Swagger2 && version != consts.OpenAPIVersion {
+		return nil, fmt.Errorf("swagger 2.0 is converted from openapi %s, but got openapi version %q", consts.OpenAPIVersion, version)
+	}
+	if g.args.Swagger2 && g.args.OutputMode == consts.OutputModeSplit {
+		return nil, fmt.Errorf("swagger 2.0 is converted from a single document, but got %s output mode", consts.OutputModeSplit)
+	}
 	d.Openapi = version
 	d.Info = &openapi.Info{
 		Title:       consts.DefaultInfoTitle + consts.PluginNameThriftHttpSwagger,
-		Description: consts.DefaultInfoDesc,
+		Description: g.defaultDescription(),
 		Version:     consts.DefaultInfoVersion,
 	}
 	d.Paths = &openapi.Paths{}
@@ -88,24 +165,35 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 	var extDocument *openapi.Document
 	err := g.getDocumentOption(&extDocument)
 	if err != nil {
-		logs.Errorf("Error merging document option: %s", err)
-		return nil
+		return nil, fmt.Errorf("failed to get document option: %w", err)
 	}
+	var declaredTags []string
 	if extDocument != nil {
 		err := common.MergeStructs(d, extDocument)
 		if err != nil {
-			logs.Errorf("Error merging document option: %s", err)
-			return nil
+			return nil, fmt.Errorf("failed to merge document option: %w", err)
+		}
+		for _, tag := range extDocument.Tags {
+			declaredTags = append(declaredTags, tag.Name)
 		}
 	}
 
-	g.addPathsToDocument(d, g.fileDesc.GetServices())
+	if err = g.addPathsToDocument(d, g.services()); err != nil {
+		return nil, err
+	}
 
 	for len(g.requiredSchemas) > 0 {
 		count := len(g.requiredSchemas)
 		g.addSchemasForStructsToDocument(d, g.requiredTypeDesc)
 		g.requiredSchemas = g.requiredSchemas[count:len(g.requiredSchemas)]
 	}
+	if g.schemaNameErr != nil {
+		return nil, g.schemaNameErr
+	}
+
+	if g.args.ReuseParameters {
+		g.addReusableParametersToDocument(d)
+	}
 
 	if len(d.Tags) == 1 {
 		if d.Info.Title == "" && d.Tags[0].Name != "" {
@@ -116,50 +204,26 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		}
 	}
 
+	addOperationTagsToDocument(d)
+
 	var allServers []string
 
 	// If paths methods has servers, but they're all the same, then move servers to path level
 	for _, path := range d.Paths.Path {
 		var servers []string
+		operations := getPathItemOperations(path.Value)
 		// Only 1 server will ever be set, per method, by the generator
-		if path.Value.Get != nil && len(path.Value.Get.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Get.Servers[0].URL)
-			allServers = common.AppendUnique(allServers, path.Value.Get.Servers[0].URL)
-		}
-		if path.Value.Post != nil && len(path.Value.Post.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Post.Servers[0].URL)
-			allServers = common.AppendUnique(allServers, path.Value.Post.Servers[0].URL)
-		}
-		if path.Value.Put != nil && len(path.Value.Put.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Put.Servers[0].URL)
-			allServers = common.AppendUnique(allServers, path.Value.Put.Servers[0].URL)
-		}
-		if path.Value.Delete != nil && len(path.Value.Delete.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Delete.Servers[0].URL)
-			allServers = common.AppendUnique(allServers, path.Value.Delete.Servers[0].URL)
-		}
-		if path.Value.Patch != nil && len(path.Value.Patch.Servers) == 1 {
-			servers = common.AppendUnique(servers, path.Value.Patch.Servers[0].URL)
-			allServers = common.AppendUnique(allServers, path.Value.Patch.Servers[0].URL)
+		for _, op := range operations {
+			if len(op.Servers) == 1 {
+				servers = common.AppendUnique(servers, op.Servers[0].URL)
+				allServers = common.AppendUnique(allServers, op.Servers[0].URL)
+			}
 		}
 
 		if len(servers) == 1 {
 			path.Value.Servers = []*openapi.Server{{URL: servers[0]}}
-
-			if path.Value.Get != nil {
-				path.Value.Get.Servers = nil
-			}
-			if path.Value.Post != nil {
-				path.Value.Post.Servers = nil
-			}
-			if path.Value.Put != nil {
-				path.Value.Put.Servers = nil
-			}
-			if path.Value.Delete != nil {
-				path.Value.Delete.Servers = nil
-			}
-			if path.Value.Patch != nil {
-				path.Value.Patch.Servers = nil
+			for _, op := range operations {
+				op.Servers = nil
 			}
 		}
 	}
@@ -179,20 +243,22 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		}
 	}
 
+	sortTags(d.Tags, declaredTags)
+
 	{
-		pairs := d.Tags
+		pairs := d.Paths.Path
 		sort.Slice(pairs, func(i, j int) bool {
 			return pairs[i].Name < pairs[j].Name
 		})
-		d.Tags = pairs
+		d.Paths.Path = pairs
 	}
 
-	{
-		pairs := d.Paths.Path
+	if d.Webhooks != nil {
+		pairs := d.Webhooks.Path
 		sort.Slice(pairs, func(i, j int) bool {
 			return pairs[i].Name < pairs[j].Name
 		})
-		d.Paths.Path = pairs
+		d.Webhooks.Path = pairs
 	}
 
 	{
@@ -203,17 +269,106 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		d.Components.Schemas.AdditionalProperties = pairs
 	}
 
-	bytes, err := d.YAMLValue("Generated with " + consts.PluginNameThriftHttpSwagger + "\n" + consts.InfoURL + consts.PluginNameThriftHttpSwagger)
+	if d.Components.SecuritySchemes != nil {
+		pairs := d.Components.SecuritySchemes.AdditionalProperties
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].Name < pairs[j].Name
+		})
+		d.Components.SecuritySchemes.AdditionalProperties = pairs
+	}
+
+	return d, nil
+}
+
+func (g *OpenAPIGenerator) BuildDocument() []*plugin.Generated {
+	d, err := g.buildDocument()
 	if err != nil {
-		logs.Errorf("Error converting to yaml: %s", err)
+		logs.Errorf("Error building document: %s", err)
 		return nil
 	}
-	outputDir := arguments.OutputDir
+
+	outputDir := g.args.OutputDir
 	if outputDir == "" {
 		outputDir = consts.DefaultOutputDir
 	}
-	filePath := filepath.Join(outputDir, consts.DefaultOutputYamlFile)
+	comment, err := g.comment()
+	if err != nil {
+		logs.Errorf("Error reading file header: %s", err)
+		return nil
+	}
+
 	var ret []*plugin.Generated
+	var bytes []byte
+	if g.args.OutputMode == consts.OutputModeSplit {
+		if g.args.InlineSchemas {
+			logs.Warnf("InlineSchemas is ignored in %s output mode", consts.OutputModeSplit)
+		}
+		var schemas map[string][]byte
+		bytes, schemas, err = d.SplitYAMLValue(comment, consts.DefaultOutputSchemaDir)
+		if err != nil {
+			logs.Errorf("Error converting to yaml: %s", err)
+			return nil
+		}
+		if g.args.PruneUnused {
+			full, err := d.YAMLValue(comment)
+			if err != nil {
+				logs.Errorf("Error converting to yaml: %s", err)
+				return nil
+			}
+			unused, err := common.UnusedSchemas(full)
+			if err != nil {
+				logs.Errorf("Error pruning schemas: %s", err)
+				return nil
+			}
+			for _, name := range unused {
+				delete(schemas, name)
+			}
+		}
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+				if schemas[name], err = common.ConvertSchemaToOpenAPI31(schemas[name]); err != nil {
+					logs.Errorf("Error converting schema to openapi %s: %s", consts.OpenAPIVersion31, err)
+					return nil
+				}
+			}
+			schemaPath := filepath.Join(outputDir, consts.DefaultOutputSchemaDir, name+".yaml")
+			ret = append(ret, &plugin.Generated{
+				Content: string(schemas[name]),
+				Name:    &schemaPath,
+			})
+		}
+		if g.args.Validate {
+			// Split schemas are referenced by relative paths, so validate the document with them inlined.
+			full, err := d.YAMLValue(comment)
+			if err != nil {
+				logs.Errorf("Error converting to yaml: %s", err)
+				return nil
+			}
+			if err = common.ValidateOpenAPI(full); err != nil {
+				logs.Errorf("Error validating openapi document: %s", err)
+				return nil
+			}
+		}
+		// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+		if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+			if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+				logs.Errorf("Error converting to openapi %s: %s", consts.OpenAPIVersion31, err)
+				return nil
+			}
+		}
+	} else {
+		bytes, err = g.marshalDocument(d, comment)
+		if err != nil {
+			logs.Errorf("Error marshaling document: %s", err)
+			return nil
+		}
+	}
+	filePath := filepath.Join(outputDir, consts.DefaultOutputYamlFile)
 	ret = append(ret, &plugin.Generated{
 		Content: string(bytes),
 		Name:    &filePath,
@@ -222,6 +377,125 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 	return ret
 }
 
+// BuildServiceDocuments builds a document for each service of the thrift file in the service output
+// mode, written to [service].openapi.yaml next to openapi.yaml. Each document is built by its own
+// generator, so the schemas shared by the services are repeated in the documents of each of them.
+func (g *OpenAPIGenerator) BuildServiceDocuments() []*plugin.Generated {
+	outputDir := g.args.OutputDir
+	if outputDir == "" {
+		outputDir = consts.DefaultOutputDir
+	}
+
+	comment, err := g.comment()
+	if err != nil {
+		logs.Errorf("Error reading file header: %s", err)
+		return nil
+	}
+
+	ret := make([]*plugin.Generated, 0)
+	for _, s := range g.fileDesc.GetServices() {
+		if isIgnored(s.Annotations) {
+			continue
+		}
+		sg, err := NewOpenAPIGenerator(g.ast, g.args)
+		if err != nil {
+			logs.Errorf("Error creating generator of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		sg.service = s.GetName()
+		d, err := sg.buildDocument()
+		if err != nil {
+			logs.Errorf("Error building document of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		bytes, err := sg.marshalDocument(d, comment)
+		if err != nil {
+			logs.Errorf("Error marshaling document of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		filePath := filepath.Join(outputDir, s.GetName()+"."+consts.DefaultOutputYamlFile)
+		ret = append(ret, &plugin.Generated{
+			Content: string(bytes),
+			Name:    &filePath,
+		})
+	}
+	return ret
+}
+
+// marshalDocument returns the YAML of a document written as a single file, with the schemas pruned
+// and inlined as configured, validated and converted to the configured OpenAPI version or to Swagger 2.0.
+func (g *OpenAPIGenerator) marshalDocument(d *openapi.Document, comment string) ([]byte, error) {
+	bytes, err := d.YAMLValue(comment)
+	if err != nil {
+		return nil, err
+	}
+	if g.args.PruneUnused {
+		if bytes, err = common.PruneSchemas(bytes); err != nil {
+			return nil, fmt.Errorf("failed to prune schemas: %w", err)
+		}
+	}
+	if g.args.InlineSchemas {
+		if bytes, err = common.InlineSchemas(bytes); err != nil {
+			return nil, fmt.Errorf("failed to inline schemas: %w", err)
+		}
+	}
+	if g.args.Validate {
+		if err = common.ValidateOpenAPI(bytes); err != nil {
+			return nil, fmt.Errorf("failed to validate openapi document: %w", err)
+		}
+	}
+	// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+	if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+		if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+			return nil, fmt.Errorf("failed to convert to openapi %s: %w", consts.OpenAPIVersion31, err)
+		}
+	}
+	// Likewise the document is converted to Swagger 2.0 after it is validated.
+	if g.args.Swagger2 {
+		if bytes, err = common.ConvertToSwagger2(bytes); err != nil {
+			return nil, fmt.Errorf("failed to convert to swagger 2.0: %w", err)
+		}
+	}
+	return bytes, nil
+}
+
+// comment returns the head comment of the generated YAML files, the banner preceded by the file header.
+func (g *OpenAPIGenerator) comment() (string, error) {
+	banner := "Generated with " + consts.PluginNameThriftHttpSwagger + "\n" + consts.InfoURL + consts.PluginNameThriftHttpSwagger
+	header, err := common.FileHeader(g.args.FileHeader)
+	if err != nil || header == "" {
+		return banner, err
+	}
+	return header + "\n\n" + banner, nil
+}
+
+// defaultDescription returns the description of the document unless the openapi.document annotation
+// sets one, which is the doc comment of the thrift file when DescriptionFromFile is set and it has one.
+func (g *OpenAPIGenerator) defaultDescription() string {
+	if g.args.DescriptionFromFile {
+		comment, err := common.FileDocComment(g.ast.Filename)
+		if err != nil {
+			logs.Warnf("Error reading the doc comment of %s: %s", g.ast.Filename, err)
+		} else if description := g.filterCommentString(comment); description != "" {
+			return description
+		}
+	}
+	return consts.DefaultInfoDesc
+}
+
+// services returns the services of the thrift file the document describes.
+func (g *OpenAPIGenerator) services() []*thrift_reflection.ServiceDescriptor {
+	if g.service == "" {
+		return g.fileDesc.GetServices()
+	}
+	for _, s := range g.fileDesc.GetServices() {
+		if s.GetName() == g.service {
+			return []*thrift_reflection.ServiceDescriptor{s}
+		}
+	}
+	return nil
+}
+
 func (g *OpenAPIGenerator) getDocumentOption(obj interface{}) error {
 	serviceOrStruct, name := g.getDocumentAnnotationInWhichServiceOrStruct()
 
@@ -249,18 +523,36 @@ func (g *OpenAPIGenerator) getDocumentOption(obj interface{}) error {
 	return nil
 }
 
-func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*thrift_reflection.ServiceDescriptor) {
+// addPathsToDocument adds the operations of the functions of the services to the document. It fails
+// on a webhook if the document isn't an OpenAPI 3.1 document, and on exceptions sharing a status code.
+func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*thrift_reflection.ServiceDescriptor) error {
 	var err error
 	for _, s := range services {
-		if s != nil {
+		if s != nil && !isIgnored(s.Annotations) {
+			// The external docs of the first service are used unless the openapi.document annotation sets them
+			if d.ExternalDocs == nil {
+				err = utils.ParseServiceOption(s, consts.OpenapiExternalDocs, &d.ExternalDocs)
+				if err != nil {
+					logs.Errorf("Error parsing service option: %s", err)
+				}
+			}
 			annotationsCount := 0
+			serviceSchemes := g.addSecuritySchemesToDocument(d, s)
+			// Functions referenced by openapi.callback are called back by the service, so they are
+			// only documented in the callbacks of the operations referencing them.
+			callbackFunctions := getCallbackFunctions(s)
+			callbackItems := map[string]*openapi.PathItem{}
+			var callbackOperations []*openapi.Operation
+			var callbackMethods []*thrift_reflection.MethodDescriptor
 			for _, m := range s.GetMethods() {
-				var inputDesc, outputDesc, throwDesc *thrift_reflection.StructDescriptor
+				var inputDesc, outputDesc *thrift_reflection.StructDescriptor
+				var throwDescs []*thrift_reflection.StructDescriptor
 
 				rs := utils.GetAnnotations(m.Annotations, HttpMethodAnnotations)
-				if len(rs) == 0 {
+				if len(rs) == 0 || isIgnored(m.Annotations) {
 					continue
 				}
+				anyMethods := expandAnyMethod(rs)
 
 				if len(m.Args) > 0 {
 					if len(m.Args) > 1 {
@@ -287,11 +579,19 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*t
 					logs.Errorf("now only support struct type for output, but got %s", m.Response.Name)
 				}
 
-				if len(m.ThrowExceptions) > 0 {
-					throwDesc, err = m.ThrowExceptions[0].GetType().GetExceptionDescriptor()
+				for _, e := range m.ThrowExceptions {
+					throwDesc, err := e.GetType().GetExceptionDescriptor()
 					if err != nil {
 						logs.Errorf("Error getting exception descriptor: %s", err)
+						continue
 					}
+					throwDescs = append(throwDescs, throwDesc)
+				}
+
+				var externalDocs *openapi.ExternalDocs
+				err = utils.ParseMethodOption(m, consts.OpenapiExternalDocs, &externalDocs)
+				if err != nil {
+					logs.Errorf("Error parsing method option: %s", err)
 				}
 
 				for methodName, path := range rs {
@@ -305,33 +605,420 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*t
 						}
 
 						annotationsCount++
-						operationID := s.GetName() + "_" + m.GetName()
+						operationID := g.getOperationID(s.GetName(), m.GetName())
+						if common.Contains(anyMethods, methodName) {
+							operationID += "_" + methodName
+						}
 						comment := g.filterCommentString(m.Comments)
 
-						op, path2 := g.buildOperation(d, methodName, comment, operationID, s.GetName(), path[0], host, inputDesc, outputDesc, throwDesc)
+						responseCode := g.getResponseCode(m, outputDesc)
+
+						op, path2, err := g.buildOperation(d, methodName, comment, operationID, getTagName(s), path[0], host, responseCode, inputDesc, outputDesc, throwDescs)
+						if err != nil {
+							return err
+						}
+						op.Deprecated = g.isDeprecated(m.Annotations)
+						op.Security = getSecurityRequirements(m.Annotations, serviceSchemes)
 
 						newOp := &openapi.Operation{}
 						err = utils.ParseMethodOption(m, consts.OpenapiOperation, &newOp)
 						if err != nil {
 							logs.Errorf("Error parsing method option: %s", err)
 						}
+						// Tags from the annotation are added to the tag of the service instead of replacing it
+						op.Tags = appendTags(op.Tags, newOp.Tags)
+						newOp.Tags = nil
+						// Responses from the annotation are merged by status code instead of replacing the generated ones
+						if newOp.Responses != nil {
+							op.Responses = mergeResponses(op.Responses, newOp.Responses)
+							newOp.Responses = nil
+						}
 						err = common.MergeStructs(op, newOp)
 						if err != nil {
 							logs.Errorf("Error merging method option: %s", err)
 						}
+						if err = addSunset(op, m.Annotations, "function '"+m.GetName()+"'"); err != nil {
+							return err
+						}
+						op.SpecificationExtension = addExtensions(op.SpecificationExtension, m.Annotations, "function '"+m.GetName()+"'")
+						if externalDocs != nil {
+							op.ExternalDocs = externalDocs
+						}
 
-						g.addOperationToDocument(d, op, path2, methodName)
+						if common.Contains(callbackFunctions, m.GetName()) {
+							if callbackItems[m.GetName()] == nil {
+								callbackItems[m.GetName()] = &openapi.PathItem{}
+							}
+							setPathItemOperation(callbackItems[m.GetName()], op, methodName)
+							continue
+						}
+						if len(m.Annotations[consts.OpenapiCallback]) > 0 {
+							callbackOperations = append(callbackOperations, op)
+							callbackMethods = append(callbackMethods, m)
+						}
+
+						if webhook := getWebhookName(m); webhook != "" {
+							// Webhooks are called by the service, so they are keyed by name instead of path
+							if g.args.OpenAPIVersion != consts.OpenAPIVersion31 {
+								return fmt.Errorf("function %s is the webhook %s, which needs OpenAPIVersion=%s", m.GetName(), webhook, consts.OpenAPIVersion31)
+							}
+							if d.Webhooks == nil {
+								d.Webhooks = &openapi.Paths{}
+							}
+							addOperationToPaths(d.Webhooks, op, webhook, methodName)
+							continue
+						}
+						addOperationToPaths(d.Paths, op, common.WithBasePath(g.args.BasePath, path2), methodName)
 					}
 				}
 			}
+			for i, op := range callbackOperations {
+				addCallbacksToOperation(op, callbackMethods[i], callbackItems)
+			}
 			if annotationsCount > 0 {
 				comment := g.filterCommentString(s.Comments)
-				d.Tags = append(d.Tags, &openapi.Tag{Name: s.GetName(), Description: comment})
+				addTagToDocument(d, &openapi.Tag{Name: getTagName(s), Description: comment})
+			}
+		}
+	}
+	return nil
+}
+
+// hasProperties reports whether the schema has any property.
+func hasProperties(schema *openapi.Schema) bool {
+	return schema != nil && schema.Properties != nil && len(schema.Properties.AdditionalProperties) > 0
+}
+
+// synthesizeExample fills the example of a primitive schema lacking one with a placeholder of its type, or
+// the first value of its enum, so that Swagger UI prefills the fields. The items of an array are filled
+// the same way. The placeholder is moved into the bounds and length limits of the schema, a string with a
+// pattern is left alone, as the placeholder may not match it.
+func (g *OpenAPIGenerator) synthesizeExample(schema *openapi.SchemaOrReference) {
+	if !g.args.SynthesizeExamples || schema == nil || !schema.IsSetSchema() {
+		return
+	}
+	s := schema.Schema
+	if s.Items != nil {
+		for _, item := range s.Items.SchemaOrReference {
+			g.synthesizeExample(item)
+		}
+	}
+	if s.Example != nil || s.Pattern != "" {
+		return
+	}
+	if len(s.Enum) > 0 {
+		s.Example = &openapi.Any{Yaml: s.Enum[0].Yaml}
+		return
+	}
+	bounds := common.ExampleBounds{
+		Minimum:          s.Minimum,
+		Maximum:          s.Maximum,
+		ExclusiveMinimum: s.ExclusiveMinimum,
+		ExclusiveMaximum: s.ExclusiveMaximum,
+		MinLength:        s.MinLength,
+		MaxLength:        s.MaxLength,
+	}
+	if example := common.ExampleForType(s.Type, s.Format, bounds); example != "" {
+		s.Example = &openapi.Any{Yaml: example}
+	}
+}
+
+// expandAnyMethod replaces the ANY method of the function with each of the common methods
+// not annotated explicitly, since an operation can't match every method, and returns them.
+func expandAnyMethod(rs map[string][]string) []string {
+	path, ok := rs[consts.HttpMethodAny]
+	if !ok {
+		return nil
+	}
+	delete(rs, consts.HttpMethodAny)
+	var methods []string
+	for _, methodName := range []string{
+		consts.HttpMethodGet, consts.HttpMethodPost, consts.HttpMethodPut,
+		consts.HttpMethodDelete, consts.HttpMethodPatch,
+	} {
+		if _, ok := rs[methodName]; !ok {
+			rs[methodName] = path
+			methods = append(methods, methodName)
+		}
+	}
+	return methods
+}
+
+// addSecuritySchemesToDocument adds the security schemes declared by the openapi.security
+// annotation of the service to the document and returns their names.
+func (g *OpenAPIGenerator) addSecuritySchemesToDocument(d *openapi.Document, s *thrift_reflection.ServiceDescriptor) []string {
+	var names []string
+	for _, v := range s.Annotations[consts.OpenapiSecurity] {
+		schemes := map[string]*openapi.SecurityScheme{}
+		if err := json.Unmarshal([]byte(v), &schemes); err != nil {
+			logs.Errorf("Error parsing security annotation of service '%s': %s", s.GetName(), err)
+			continue
+		}
+		for name, scheme := range schemes {
+			names = common.AppendUnique(names, name)
+			if d.Components.SecuritySchemes == nil {
+				d.Components.SecuritySchemes = &openapi.SecuritySchemesOrReferences{}
+			}
+			exists := false
+			for _, pair := range d.Components.SecuritySchemes.AdditionalProperties {
+				if pair.Name == name {
+					exists = true
+					break
+				}
+			}
+			if exists {
+				continue
+			}
+			d.Components.SecuritySchemes.AdditionalProperties = append(d.Components.SecuritySchemes.AdditionalProperties,
+				&openapi.NamedSecuritySchemeOrReference{
+					Name:  name,
+					Value: &openapi.SecuritySchemeOrReference{SecurityScheme: scheme},
+				})
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getSecurityRequirements builds the security requirements of a function from its openapi.security
+// annotation, each value being a scheme name optionally followed by its scopes ("name:scope1,scope2").
+// Functions without the annotation accept any of the schemes declared on their service, and an
+// annotation without schemes makes the function public, overriding the security of the document.
+func getSecurityRequirements(annotations map[string][]string, serviceSchemes []string) []*openapi.SecurityRequirement {
+	values, ok := annotations[consts.OpenapiSecurity]
+	if !ok {
+		values = serviceSchemes
+	}
+	var requirements []*openapi.SecurityRequirement
+	for _, v := range values {
+		parts := strings.SplitN(v, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			continue
+		}
+		scopes := []string{}
+		if len(parts) == 2 {
+			for _, scope := range strings.Split(parts[1], ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+		requirements = append(requirements, &openapi.SecurityRequirement{
+			AdditionalProperties: []*openapi.NamedStringArray{
+				{Name: name, Value: &openapi.StringArray{Values: scopes}},
+			},
+		})
+	}
+	if ok && requirements == nil {
+		// An empty list is emitted as security: [], unlike nil
+		return []*openapi.SecurityRequirement{}
+	}
+	return requirements
+}
+
+// addReusableParametersToDocument moves the parameters shared identically by more than one operation
+// to the components and replaces them with references.
+func (g *OpenAPIGenerator) addReusableParametersToDocument(d *openapi.Document) {
+	var operations []*openapi.Operation
+	for _, path := range d.Paths.Path {
+		item := path.Value
+		for _, op := range []*openapi.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if op != nil {
+				operations = append(operations, op)
+			}
+		}
+	}
+
+	// Parameters are keyed by name, only the first variant of a name is reused
+	var names []string
+	parameters := map[string]*openapi.Parameter{}
+	counts := map[string]int{}
+	nameRe := regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+	for _, op := range operations {
+		for _, p := range op.Parameters {
+			if p.Parameter == nil {
+				continue
+			}
+			name := nameRe.ReplaceAllString(p.Parameter.Name, "_")
+			if _, ok := parameters[name]; !ok {
+				parameters[name] = p.Parameter
+				names = append(names, name)
+			}
+			if reflect.DeepEqual(parameters[name], p.Parameter) {
+				counts[name]++
+			}
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		if counts[name] < 2 {
+			continue
+		}
+		for _, op := range operations {
+			for i, p := range op.Parameters {
+				if p.Parameter != nil && reflect.DeepEqual(parameters[name], p.Parameter) {
+					op.Parameters[i] = &openapi.ParameterOrReference{
+						Reference: &openapi.Reference{Xref: consts.ComponentParameterPrefix + name},
+					}
+				}
+			}
+		}
+		if d.Components.Parameters == nil {
+			d.Components.Parameters = &openapi.ParametersOrReferences{}
+		}
+		d.Components.Parameters.AdditionalProperties = append(d.Components.Parameters.AdditionalProperties,
+			&openapi.NamedParameterOrReference{
+				Name:  name,
+				Value: &openapi.ParameterOrReference{Parameter: parameters[name]},
+			})
+	}
+}
+
+// getWebhookName returns the name of the webhook set by the openapi.webhook annotation of the
+// function, or an empty string if the function is not a webhook.
+func getWebhookName(m *thrift_reflection.MethodDescriptor) string {
+	if names, ok := m.Annotations[consts.OpenapiWebhook]; ok && len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// getCallbackFunctions returns the functions of the service referenced by the openapi.callback
+// annotations of its functions.
+func getCallbackFunctions(s *thrift_reflection.ServiceDescriptor) []string {
+	var functions []string
+	for _, m := range s.GetMethods() {
+		for _, callback := range m.Annotations[consts.OpenapiCallback] {
+			if fields := strings.Fields(callback); len(fields) == 3 {
+				functions = common.AppendUnique(functions, fields[2])
+			}
+		}
+	}
+	return functions
+}
+
+// addCallbacksToOperation adds the callbacks declared by the openapi.callback annotations of the
+// function to the operation. Each annotation holds the callback name, the runtime expression of
+// the callback URL and the function describing the callback request, separated by spaces, e.g.
+// openapi.callback = "paid {$request.body#/callback_url} NotifyPaid".
+func addCallbacksToOperation(op *openapi.Operation, m *thrift_reflection.MethodDescriptor, callbackItems map[string]*openapi.PathItem) {
+	for _, callback := range m.Annotations[consts.OpenapiCallback] {
+		fields := strings.Fields(callback)
+		if len(fields) != 3 {
+			logs.Errorf("Invalid callback %q of function %s, expected the name, the expression and the function separated by spaces", callback, m.GetName())
+			continue
+		}
+		item := callbackItems[fields[2]]
+		if item == nil {
+			logs.Errorf("Callback %s of function %s references %s, which is not an HTTP function of the service", fields[0], m.GetName(), fields[2])
+			continue
+		}
+		if op.Callbacks == nil {
+			op.Callbacks = &openapi.CallbacksOrReferences{}
+		}
+		op.Callbacks.AdditionalProperties = append(op.Callbacks.AdditionalProperties, &openapi.NamedCallbackOrReference{
+			Name: fields[0],
+			Value: &openapi.CallbackOrReference{
+				Callback: &openapi.Callback{
+					Path: []*openapi.NamedPathItem{{Name: fields[1], Value: item}},
+				},
+			},
+		})
+	}
+}
+
+// getTagName returns the tag of the operations of the service, the service name unless
+// the openapi.tag annotation overrides it, so that several services can share a tag.
+func getTagName(s *thrift_reflection.ServiceDescriptor) string {
+	if tags, ok := s.Annotations[consts.OpenapiTag]; ok && len(tags) > 0 && tags[0] != "" {
+		return tags[0]
+	}
+	return s.GetName()
+}
+
+// addTagToDocument adds the tag of a service to the document, a tag with the same name declared
+// in the openapi.document annotation is kept and only takes the description if it has none.
+func addTagToDocument(d *openapi.Document, tag *openapi.Tag) {
+	for _, t := range d.Tags {
+		if t.Name == tag.Name {
+			if t.Description == "" {
+				t.Description = tag.Description
+			}
+			return
+		}
+	}
+	d.Tags = append(d.Tags, tag)
+}
+
+// appendTags appends the tags that aren't in the list yet, keeping their order.
+func appendTags(tags, more []string) []string {
+	for _, tag := range more {
+		tags = common.AppendUnique(tags, tag)
+	}
+	return tags
+}
+
+// addOperationTagsToDocument adds the tags the operations carry besides the tags of their services, e.g.
+// admin or beta, to the document, so that every tag referenced by an operation is listed.
+func addOperationTagsToDocument(d *openapi.Document) {
+	for _, paths := range []*openapi.Paths{d.Paths, d.Webhooks} {
+		if paths == nil {
+			continue
+		}
+		for _, path := range paths.Path {
+			for _, op := range getPathItemOperations(path.Value) {
+				for _, tag := range op.Tags {
+					addTagToDocument(d, &openapi.Tag{Name: tag})
+				}
 			}
 		}
 	}
 }
 
+// sortTags sorts the tags declared in the openapi.document annotation first, in their declared
+// order, followed by the other tags in alphabetical order.
+func sortTags(tags []*openapi.Tag, declared []string) {
+	order := make(map[string]int, len(declared))
+	for i, name := range declared {
+		if _, ok := order[name]; !ok {
+			order[name] = i
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		oi, iok := order[tags[i].Name]
+		oj, jok := order[tags[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		if iok || jok {
+			return iok
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// getParameterBinding returns the name and the location of the parameter the field is bound to
+// by the api.query, api.path, api.cookie or api.header annotation, the last of them in this order
+// taking precedence.
+func getParameterBinding(field *thrift_reflection.FieldDescriptor) (string, string) {
+	for _, binding := range []struct {
+		annotation string
+		in         string
+	}{
+		{consts.ApiHeader, consts.ParameterInHeader},
+		{consts.ApiCookie, consts.ParameterInCookie},
+		{consts.ApiPath, consts.ParameterInPath},
+		{consts.ApiQuery, consts.ParameterInQuery},
+	} {
+		if values := field.Annotations[binding.annotation]; len(values) > 0 && values[0] != "" {
+			return values[0], binding.in
+		}
+	}
+	return "", ""
+}
+
 func (g *OpenAPIGenerator) buildOperation(
 	d *openapi.Document,
 	methodName string,
@@ -340,10 +1027,11 @@ func (g *OpenAPIGenerator) buildOperation(
 	tagName string,
 	path string,
 	host string,
+	responseCode string,
 	inputDesc *thrift_reflection.StructDescriptor,
 	outputDesc *thrift_reflection.StructDescriptor,
-	throwDesc *thrift_reflection.StructDescriptor,
-) (*openapi.Operation, string) {
+	throwDescs []*thrift_reflection.StructDescriptor,
+) (*openapi.Operation, string, error) {
 	// Parameters array to hold all parameter objects
 	var parameters []*openapi.ParameterOrReference
 
@@ -351,121 +1039,99 @@ func (g *OpenAPIGenerator) buildOperation(
 
 	if inputDesc != nil {
 		for _, v := range inputDesc.GetFields() {
-			var paramName, paramIn, paramDesc string
-			var fieldSchema *openapi.SchemaOrReference
-			required := false
-
-			extOrNil := v.Annotations[consts.ApiQuery]
-			if len(extOrNil) > 0 {
-				if ext := v.Annotations[consts.ApiQuery][0]; ext != "" {
-					paramIn = consts.ParameterInQuery
-					paramName = ext
-					paramDesc = g.filterCommentString(v.Comments)
-					fieldSchema = g.schemaOrReferenceForField(v.Type)
-					extPropertyOrNil := v.Annotations[consts.OpenapiProperty]
-					if len(extPropertyOrNil) > 0 && fieldSchema.IsSetSchema() {
-						newFieldSchema := &openapi.Schema{}
-						err := utils.ParseFieldOption(v, consts.OpenapiProperty, &newFieldSchema)
-						if err != nil {
-							logs.Errorf("Error parsing field option: %s", err)
-						}
-						common.MergeStructs(fieldSchema.Schema, newFieldSchema)
-					}
-				}
+			paramName, paramIn := getParameterBinding(v)
+			if paramIn == "" {
+				continue
 			}
-			extOrNil = v.Annotations[consts.ApiPath]
-			if len(extOrNil) > 0 {
-				if ext := v.Annotations[consts.ApiPath][0]; ext != "" {
-					paramIn = consts.ParameterInPath
-					paramName = ext
-					paramDesc = g.filterCommentString(v.Comments)
-					fieldSchema = g.schemaOrReferenceForField(v.Type)
-					extPropertyOrNil := v.Annotations[consts.OpenapiProperty]
-					if len(extPropertyOrNil) > 0 && fieldSchema.IsSetSchema() {
-						newFieldSchema := &openapi.Schema{}
-						err := utils.ParseFieldOption(v, consts.OpenapiProperty, &newFieldSchema)
-						if err != nil {
-							logs.Errorf("Error parsing field option: %s", err)
-						}
-						common.MergeStructs(fieldSchema.Schema, newFieldSchema)
-					}
-					required = true
+
+			// The schema is built once for the parameter, and the annotations are merged into it in
+			// the order of precedence: openapi.property overrides the schema of the field type, and
+			// the schema of openapi.parameter overrides both
+			fieldSchema := g.schemaOrReferenceForField(v.Type)
+			applyValidateAnnotation(fieldSchema, v.Annotations)
+			applyFormatAnnotation(fieldSchema, v)
+			applyConstAnnotations(fieldSchema, v)
+			if len(v.Annotations[consts.OpenapiProperty]) > 0 && fieldSchema != nil && fieldSchema.IsSetSchema() {
+				newFieldSchema := &openapi.Schema{}
+				err := utils.ParseFieldOption(v, consts.OpenapiProperty, &newFieldSchema)
+				if err != nil {
+					logs.Errorf("Error parsing field option: %s", err)
 				}
+				common.MergeStructs(fieldSchema.Schema, newFieldSchema)
 			}
-			extOrNil = v.Annotations[consts.ApiCookie]
-			if len(extOrNil) > 0 {
-				if ext := v.Annotations[consts.ApiCookie][0]; ext != "" {
-					paramIn = consts.ParameterInCookie
-					paramName = ext
-					paramDesc = g.filterCommentString(v.Comments)
-					fieldSchema = g.schemaOrReferenceForField(v.Type)
-					extPropertyOrNil := v.Annotations[consts.OpenapiProperty]
-					if len(extPropertyOrNil) > 0 && fieldSchema.IsSetSchema() {
-						newFieldSchema := &openapi.Schema{}
-						err := utils.ParseFieldOption(v, consts.OpenapiProperty, &newFieldSchema)
-						if err != nil {
-							logs.Errorf("Error parsing field option: %s", err)
-						}
-						common.MergeStructs(fieldSchema.Schema, newFieldSchema)
-					}
-				}
+
+			var extParameter *openapi.Parameter
+			err := utils.ParseFieldOption(v, consts.OpenapiParameter, &extParameter)
+			if err != nil {
+				logs.Errorf("Error parsing field option: %s", err)
 			}
-			extOrNil = v.Annotations[consts.ApiHeader]
-			if len(extOrNil) > 0 {
-				if ext := v.Annotations[consts.ApiHeader][0]; ext != "" {
-					paramIn = consts.ParameterInHeader
-					paramName = ext
-					paramDesc = g.filterCommentString(v.Comments)
-					fieldSchema = g.schemaOrReferenceForField(v.Type)
-					extPropertyOrNil := v.Annotations[consts.OpenapiProperty]
-					if len(extPropertyOrNil) > 0 && fieldSchema.IsSetSchema() {
-						newFieldSchema := &openapi.Schema{}
-						err := utils.ParseFieldOption(v, consts.OpenapiProperty, &newFieldSchema)
-						if err != nil {
-							logs.Errorf("Error parsing field option: %s", err)
-						}
-						common.MergeStructs(fieldSchema.Schema, newFieldSchema)
-					}
-				}
+			// A schema of the annotation is merged into the field schema rather than replacing it,
+			// a reference still replaces it
+			if extParameter != nil && extParameter.Schema != nil && extParameter.Schema.IsSetSchema() &&
+				fieldSchema != nil && fieldSchema.IsSetSchema() {
+				common.MergeStructs(fieldSchema.Schema, extParameter.Schema.Schema)
+				extParameter.Schema = nil
 			}
+			g.synthesizeExample(fieldSchema)
 
 			parameter := &openapi.Parameter{
 				Name:        paramName,
 				In:          paramIn,
-				Description: paramDesc,
-				Required:    required,
-				Schema:      fieldSchema,
+				Description: g.filterCommentString(v.Comments),
+				// According to the OpenAPI specification, if a path parameter exists, it must be required.
+				Required:   paramIn == consts.ParameterInPath,
+				Deprecated: g.isDeprecated(v.Annotations),
+				Schema:     fieldSchema,
 			}
-
-			var extParameter *openapi.Parameter
-			err := utils.ParseFieldOption(v, consts.OpenapiParameter, &extParameter)
-			if err != nil {
-				logs.Errorf("Error parsing field option: %s", err)
+			setParameterStyle(parameter)
+			if paramIn == consts.ParameterInCookie && isComplexParameter(parameter) {
+				logs.Warnf("cookie parameter '%s' of struct '%s' is not a primitive type, a cookie only carries a string", paramName, inputDesc.GetName())
 			}
-			common.MergeStructs(parameter, extParameter)
 
-			// Append the parameter to the parameters array if it was set
-			if paramName != "" && paramIn != "" {
-				parameters = append(parameters, &openapi.ParameterOrReference{
-					Parameter: parameter,
-				})
+			common.MergeStructs(parameter, extParameter)
+			// A style set by the annotation comes with its own explode, which may be false
+			if extParameter != nil && extParameter.Style != "" {
+				parameter.Explode = extParameter.Explode
 			}
+			parameter.SpecificationExtension = addExtensions(parameter.SpecificationExtension, v.Annotations, "field '"+v.GetName()+"'")
+
+			parameters = append(parameters, &openapi.ParameterOrReference{
+				Parameter: parameter,
+			})
 		}
 
 		if methodName != consts.HttpMethodGet && methodName != consts.HttpMethodHead && methodName != consts.HttpMethodDelete {
 			var additionalProperties []*openapi.NamedMediaType
 
 			bodySchema := g.getSchemaByOption(inputDesc, consts.ApiBody)
+			formSchema := g.getSchemaByOption(inputDesc, consts.ApiForm)
+			bodyField := getWholeBodyField(inputDesc)
+
+			if bodyField == nil && hasProperties(bodySchema) && hasProperties(formSchema) {
+				// A body can't be JSON and a form at once, so the JSON fields are bound as form fields too
+				logs.Warnf("struct '%s' mixes api.body and api.form fields, its body is documented as a form", inputDesc.GetName())
+				formSchema.Properties.AdditionalProperties = append(formSchema.Properties.AdditionalProperties, bodySchema.Properties.AdditionalProperties...)
+				formSchema.Required = append(formSchema.Required, bodySchema.Required...)
+				bodySchema = nil
+			}
 
-			if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
+			if bodyField != nil {
+				// The struct of the field is bound as the whole body, so it's referenced directly
+				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
+					Name: consts.ContentTypeJSON,
+					Value: &openapi.MediaType{
+						Schema: g.schemaOrReferenceForField(bodyField.GetType()),
+					},
+				})
+			} else if hasProperties(bodySchema) {
 				bodyRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  inputDesc.GetName() + consts.ComponentSchemaSuffixBody,
+					Name:  g.getSchemaName(inputDesc) + g.bodySchemaSuffix,
 					Value: &openapi.SchemaOrReference{Schema: bodySchema},
 				}
 
-				bodyRef := consts.ComponentSchemaPrefix + inputDesc.GetName() + consts.ComponentSchemaSuffixBody
+				bodyRef := consts.ComponentSchemaPrefix + g.getSchemaName(inputDesc) + g.bodySchemaSuffix
 
-				g.addSchemaToDocument(d, bodyRefSchema)
+				g.addDerivedSchemaToDocument(d, bodyRefSchema, "body of "+g.getSchemaName(inputDesc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 					Name: consts.ContentTypeJSON,
@@ -473,21 +1139,20 @@ func (g *OpenAPIGenerator) buildOperation(
 						Schema: &openapi.SchemaOrReference{
 							Reference: &openapi.Reference{Xref: bodyRef},
 						},
+						Example: bodySchema.PropertiesExample(),
 					},
 				})
 			}
 
-			formSchema := g.getSchemaByOption(inputDesc, consts.ApiForm)
-
-			if formSchema != nil && formSchema.Properties != nil && len(formSchema.Properties.AdditionalProperties) > 0 {
+			if hasProperties(formSchema) {
 				formRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  inputDesc.GetName() + consts.ComponentSchemaSuffixForm,
+					Name:  g.getSchemaName(inputDesc) + g.formSchemaSuffix,
 					Value: &openapi.SchemaOrReference{Schema: formSchema},
 				}
 
-				formRef := consts.ComponentSchemaPrefix + inputDesc.GetName() + consts.ComponentSchemaSuffixForm
+				formRef := consts.ComponentSchemaPrefix + g.getSchemaName(inputDesc) + g.formSchemaSuffix
 
-				g.addSchemaToDocument(d, formRefSchema)
+				g.addDerivedSchemaToDocument(d, formRefSchema, "form of "+g.getSchemaName(inputDesc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 					Name: consts.ContentTypeFormMultipart,
@@ -495,6 +1160,8 @@ func (g *OpenAPIGenerator) buildOperation(
 						Schema: &openapi.SchemaOrReference{
 							Reference: &openapi.Reference{Xref: formRef},
 						},
+						Example:  formSchema.PropertiesExample(),
+						Encoding: getFileEncodings(formSchema),
 					},
 				})
 
@@ -504,6 +1171,7 @@ func (g *OpenAPIGenerator) buildOperation(
 						Schema: &openapi.SchemaOrReference{
 							Reference: &openapi.Reference{Xref: formRef},
 						},
+						Example: formSchema.PropertiesExample(),
 					},
 				})
 			}
@@ -512,16 +1180,16 @@ func (g *OpenAPIGenerator) buildOperation(
 
 			if rawBodySchema != nil && rawBodySchema.Properties != nil && len(rawBodySchema.Properties.AdditionalProperties) > 0 {
 				rawBodyRefSchema := &openapi.NamedSchemaOrReference{
-					Name:  inputDesc.GetName() + consts.ComponentSchemaSuffixRawBody,
+					Name:  g.getSchemaName(inputDesc) + g.rawBodySchemaSuffix,
 					Value: &openapi.SchemaOrReference{Schema: rawBodySchema},
 				}
 
-				rawBodyRef := consts.ComponentSchemaPrefix + inputDesc.GetName() + consts.ComponentSchemaSuffixRawBody
+				rawBodyRef := consts.ComponentSchemaPrefix + g.getSchemaName(inputDesc) + g.rawBodySchemaSuffix
 
-				g.addSchemaToDocument(d, rawBodyRefSchema)
+				g.addDerivedSchemaToDocument(d, rawBodyRefSchema, "raw body of "+g.getSchemaName(inputDesc))
 
 				additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
-					Name: consts.ContentTypeRawBody,
+					Name: getRawBodyContentType(inputDesc),
 					Value: &openapi.MediaType{
 						Schema: &openapi.SchemaOrReference{
 							Reference: &openapi.Reference{Xref: rawBodyRef},
@@ -537,6 +1205,7 @@ func (g *OpenAPIGenerator) buildOperation(
 						Content: &openapi.MediaTypes{
 							AdditionalProperties: additionalProperties,
 						},
+						Required: isRequestBodyRequired(inputDesc, bodySchema, formSchema, rawBodySchema),
 					},
 				}
 			}
@@ -547,17 +1216,33 @@ func (g *OpenAPIGenerator) buildOperation(
 	var responses *openapi.Responses
 
 	if outputDesc != nil {
-		response := g.processResponse(d, outputDesc, consts.StatusOK)
+		response := g.processResponse(d, outputDesc, responseCode)
 		if response != nil {
 			if responses == nil {
 				responses = &openapi.Responses{}
 			}
 			responses.ResponseOrReference = append(responses.ResponseOrReference, response)
 		}
+	} else if responseCode == consts.StatusNoContent {
+		responses = &openapi.Responses{
+			ResponseOrReference: []*openapi.NamedResponseOrReference{
+				{
+					Name: responseCode,
+					Value: &openapi.ResponseOrReference{
+						Response: &openapi.Response{Description: consts.DefaultResponseDesc},
+					},
+				},
+			},
+		}
 	}
 
-	if throwDesc != nil {
-		response := g.processResponse(d, throwDesc, consts.StatusBadRequest)
+	for i, throwDesc := range throwDescs {
+		statusCode := getExceptionCode(throwDesc, i)
+		if responses != nil && hasResponse(responses, statusCode) {
+			return nil, "", fmt.Errorf("exception %s of operation %s uses the status code %s of another response, set another one with %s on the exception",
+				throwDesc.GetName(), operationID, statusCode, consts.ApiResponseCode)
+		}
+		response := g.processResponse(d, throwDesc, statusCode)
 		if response != nil {
 			if responses == nil {
 				responses = &openapi.Responses{}
@@ -585,7 +1270,7 @@ func (g *OpenAPIGenerator) buildOperation(
 		op.Servers = append(op.Servers, &openapi.Server{URL: host})
 	}
 
-	return op, path
+	return op, path, nil
 }
 
 func (g *OpenAPIGenerator) processResponse(d *openapi.Document, desc *thrift_reflection.StructDescriptor, statusCode string) *openapi.NamedResponseOrReference {
@@ -593,7 +1278,7 @@ func (g *OpenAPIGenerator) processResponse(d *openapi.Document, desc *thrift_ref
 	description := g.filterCommentString(desc.Comments)
 
 	if description == "" {
-		if statusCode == consts.StatusOK {
+		if strings.HasPrefix(statusCode, "2") {
 			description = consts.DefaultResponseDesc
 		} else {
 			description = consts.DefaultExceptionDesc
@@ -606,11 +1291,12 @@ func (g *OpenAPIGenerator) processResponse(d *openapi.Document, desc *thrift_ref
 	}
 
 	var contentOrEmpty *openapi.MediaTypes
-	if content != nil && len(content.AdditionalProperties) != 0 {
+	// A 204 response never carries a body
+	if content != nil && len(content.AdditionalProperties) != 0 && statusCode != consts.StatusNoContent {
 		contentOrEmpty = content
 	}
 
-	if headerOrEmpty == nil && contentOrEmpty == nil {
+	if headerOrEmpty == nil && contentOrEmpty == nil && statusCode != consts.StatusNoContent {
 		return nil
 	}
 
@@ -626,6 +1312,68 @@ func (g *OpenAPIGenerator) processResponse(d *openapi.Document, desc *thrift_ref
 	}
 }
 
+// getResponseCode returns the status code of the successful response, which can be set by
+// the `api.response_code` annotation on the method or on the response struct.
+func (g *OpenAPIGenerator) getResponseCode(m *thrift_reflection.MethodDescriptor, outputDesc *thrift_reflection.StructDescriptor) string {
+	if codes := m.Annotations[consts.ApiResponseCode]; len(codes) > 0 && codes[0] != "" {
+		return codes[0]
+	}
+	if outputDesc != nil {
+		if codes := outputDesc.Annotations[consts.ApiResponseCode]; len(codes) > 0 && codes[0] != "" {
+			return codes[0]
+		}
+	}
+	return consts.StatusOK
+}
+
+// getExceptionCode returns the status code of the response of the i-th exception thrown by a
+// function, which can be set by the `api.response_code` annotation on the exception. The first
+// exception defaults to 400 and the others to 500.
+func getExceptionCode(desc *thrift_reflection.StructDescriptor, i int) string {
+	if codes := desc.Annotations[consts.ApiResponseCode]; len(codes) > 0 && codes[0] != "" {
+		return codes[0]
+	}
+	if i == 0 {
+		return consts.StatusBadRequest
+	}
+	return consts.StatusInternalServerError
+}
+
+// hasResponse reports whether the responses contain a response for the status code.
+func hasResponse(responses *openapi.Responses, statusCode string) bool {
+	for _, r := range responses.ResponseOrReference {
+		if r.Name == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeResponses merges the responses of src into dst, responses with the same status code are replaced.
+func mergeResponses(dst, src *openapi.Responses) *openapi.Responses {
+	if dst == nil {
+		return src
+	}
+	if src.Default != nil {
+		dst.Default = src.Default
+	}
+	for _, response := range src.ResponseOrReference {
+		replaced := false
+		for i, existing := range dst.ResponseOrReference {
+			if existing.Name == response.Name {
+				dst.ResponseOrReference[i] = response
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dst.ResponseOrReference = append(dst.ResponseOrReference, response)
+		}
+	}
+	dst.SpecificationExtension = append(dst.SpecificationExtension, src.SpecificationExtension...)
+	return dst
+}
+
 func (g *OpenAPIGenerator) getDocumentAnnotationInWhichServiceOrStruct() (string, string) {
 	var ret string
 	for _, s := range g.ast.Services {
@@ -656,8 +1404,10 @@ func (g *OpenAPIGenerator) getResponseForStruct(d *openapi.Document, desc *thrif
 			headerName := ext
 			header := &openapi.Header{
 				Description: g.filterCommentString(field.Comments),
+				Deprecated:  g.isDeprecated(field.Annotations),
 				Schema:      g.schemaOrReferenceForField(field.Type),
 			}
+			g.synthesizeExample(header.Schema)
 			headers.AdditionalProperties = append(headers.AdditionalProperties, &openapi.NamedHeaderOrReference{
 				Name: headerName,
 				Value: &openapi.HeaderOrReference{
@@ -674,30 +1424,31 @@ func (g *OpenAPIGenerator) getResponseForStruct(d *openapi.Document, desc *thrif
 
 	if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  desc.GetName() + consts.ComponentSchemaSuffixBody,
+			Name:  g.getSchemaName(desc) + g.bodySchemaSuffix,
 			Value: &openapi.SchemaOrReference{Schema: bodySchema},
 		}
-		ref := consts.ComponentSchemaPrefix + desc.GetName() + consts.ComponentSchemaSuffixBody
-		g.addSchemaToDocument(d, refSchema)
+		ref := consts.ComponentSchemaPrefix + g.getSchemaName(desc) + g.bodySchemaSuffix
+		g.addDerivedSchemaToDocument(d, refSchema, "body of "+g.getSchemaName(desc))
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 			Name: consts.ContentTypeJSON,
 			Value: &openapi.MediaType{
 				Schema: &openapi.SchemaOrReference{
 					Reference: &openapi.Reference{Xref: ref},
 				},
+				Example: bodySchema.PropertiesExample(),
 			},
 		})
 	}
 
 	if rawBodySchema != nil && len(rawBodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  desc.GetName() + consts.ComponentSchemaSuffixRawBody,
+			Name:  g.getSchemaName(desc) + g.rawBodySchemaSuffix,
 			Value: &openapi.SchemaOrReference{Schema: rawBodySchema},
 		}
-		ref := consts.ComponentSchemaPrefix + desc.GetName() + consts.ComponentSchemaSuffixRawBody
-		g.addSchemaToDocument(d, refSchema)
+		ref := consts.ComponentSchemaPrefix + g.getSchemaName(desc) + g.rawBodySchemaSuffix
+		g.addDerivedSchemaToDocument(d, refSchema, "raw body of "+g.getSchemaName(desc))
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
-			Name: consts.ContentTypeRawBody,
+			Name: getRawBodyContentType(desc),
 			Value: &openapi.MediaType{
 				Schema: &openapi.SchemaOrReference{
 					Reference: &openapi.Reference{Xref: ref},
@@ -713,6 +1464,43 @@ func (g *OpenAPIGenerator) getResponseForStruct(d *openapi.Document, desc *thrif
 	return headers, content
 }
 
+// getFileEncodings returns the encodings of the multipart parts of the binary properties of the
+// form schema, so that they are uploaded as files.
+func getFileEncodings(formSchema *openapi.Schema) *openapi.Encodings {
+	var encodings []*openapi.NamedEncoding
+	for _, property := range formSchema.Properties.AdditionalProperties {
+		schema := property.Value.Schema
+		// A list of binary fields uploads several files in the same part
+		if schema != nil && schema.Type == "array" && schema.Items != nil && len(schema.Items.SchemaOrReference) == 1 {
+			schema = schema.Items.SchemaOrReference[0].Schema
+		}
+		if schema != nil && schema.Type == "string" && schema.Format == "binary" {
+			encodings = append(encodings, &openapi.NamedEncoding{
+				Name:  property.Name,
+				Value: &openapi.Encoding{ContentType: consts.ContentTypeOctetStream},
+			})
+		}
+	}
+	if len(encodings) == 0 {
+		return nil
+	}
+	return &openapi.Encodings{AdditionalProperties: encodings}
+}
+
+// getRawBodyContentType returns the media type of the raw body of the struct, which can be set by
+// the `api.content_type` annotation on a raw body field and defaults to text/plain.
+func getRawBodyContentType(desc *thrift_reflection.StructDescriptor) string {
+	for _, field := range desc.GetFields() {
+		if field.Annotations[consts.ApiRawBody] == nil {
+			continue
+		}
+		if types := field.Annotations[consts.ApiContentType]; len(types) > 0 && types[0] != "" {
+			return types[0]
+		}
+	}
+	return consts.ContentTypeRawBody
+}
+
 func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.StructDescriptor, option string) *openapi.Schema {
 	definitionProperties := &openapi.Properties{
 		AdditionalProperties: make([]*openapi.NamedSchemaOrReference, 0),
@@ -749,8 +1537,23 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 				continue
 			}
 
+			deprecated := g.isDeprecated(field.Annotations)
+			readOnly, writeOnly := accessMode(field)
+			// A $ref can't carry siblings, so wrap it with `allOf` to mark it deprecated, read-only or write-only or extend it
+			if (deprecated || readOnly || writeOnly || hasExtensions(field.Annotations)) && fieldSchema.IsSetReference() {
+				fieldSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{
+					AllOf: []*openapi.SchemaOrReference{fieldSchema},
+				}}
+			}
+
 			if fieldSchema.IsSetSchema() {
 				fieldSchema.Schema.Description = description
+				fieldSchema.Schema.Deprecated = deprecated
+				fieldSchema.Schema.ReadOnly = readOnly
+				fieldSchema.Schema.WriteOnly = writeOnly
+				applyValidateAnnotation(fieldSchema, field.Annotations)
+				applyFormatAnnotation(fieldSchema, field)
+				applyConstAnnotations(fieldSchema, field)
 				newFieldSchema := &openapi.Schema{}
 				err := utils.ParseFieldOption(field, consts.OpenapiProperty, &newFieldSchema)
 				if err != nil {
@@ -760,7 +1563,9 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 				if err != nil {
 					logs.Errorf("Error merging field option: %s", err)
 				}
+				fieldSchema.Schema.SpecificationExtension = addExtensions(fieldSchema.Schema.SpecificationExtension, field.Annotations, "field '"+field.GetName()+"'")
 			}
+			g.synthesizeExample(fieldSchema)
 
 			definitionProperties.AdditionalProperties = append(
 				definitionProperties.AdditionalProperties,
@@ -783,13 +1588,293 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 			logs.Errorf("Error merging struct option: %s", err)
 		}
 	}
+	schema.SpecificationExtension = addExtensions(schema.SpecificationExtension, inputDesc.Annotations, "struct '"+inputDesc.GetName()+"'")
+	g.closeSchema(schema)
 
 	schema.Required = required
 	return schema
 }
 
+// setParameterStyle sets the serialization style of an array parameter to the default of its location,
+// so that clients know whether the values are repeated or joined.
+func setParameterStyle(parameter *openapi.Parameter) {
+	schema := parameter.Schema
+	if !(schema != nil && schema.IsSetSchema() && schema.Schema.Type == "array") {
+		return
+	}
+	switch parameter.In {
+	case consts.ParameterInQuery, consts.ParameterInCookie:
+		parameter.Style = "form"
+		parameter.Explode = true
+	case consts.ParameterInPath, consts.ParameterInHeader:
+		parameter.Style = "simple"
+	}
+}
+
+// isComplexParameter reports whether the parameter is an array or an object rather than a primitive value.
+func isComplexParameter(parameter *openapi.Parameter) bool {
+	schema := parameter.Schema
+	return schema != nil && (schema.IsSetReference() || schema.Schema.Type == "array" || schema.Schema.Type == consts.SchemaObjectType)
+}
+
+// isRequestBodyRequired reports whether a field bound to the request body is declared `required`
+// or listed in the `required` of the body schemas.
+func isRequestBodyRequired(desc *thrift_reflection.StructDescriptor, schemas ...*openapi.Schema) bool {
+	for _, field := range desc.GetFields() {
+		if !field.IsRequired() {
+			continue
+		}
+		for _, option := range []string{consts.ApiBody, consts.ApiForm, consts.ApiRawBody} {
+			if field.Annotations[option] != nil {
+				return true
+			}
+		}
+	}
+	for _, schema := range schemas {
+		if schema != nil && len(schema.Required) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getWholeBodyField returns the field bound as the whole request body, that is the only `api.body` field
+// of the struct when it has no name in the annotation and its type is a struct.
+func getWholeBodyField(desc *thrift_reflection.StructDescriptor) *thrift_reflection.FieldDescriptor {
+	var bodyField *thrift_reflection.FieldDescriptor
+	for _, field := range desc.GetFields() {
+		if values, ok := field.Annotations[consts.ApiBody]; ok {
+			if bodyField != nil || (len(values) > 0 && values[0] != "") {
+				return nil
+			}
+			bodyField = field
+		}
+	}
+	if bodyField == nil || !bodyField.GetType().IsStruct() {
+		return nil
+	}
+	return bodyField
+}
+
+// isIgnored reports whether the annotations hide a service or function from the document, e.g. internal
+// routes. The structs only they reference aren't added to the components either.
+func isIgnored(annotations map[string][]string) bool {
+	return utils.IsAnnotationEnabled(annotations, consts.OpenapiIgnore, consts.ApiNone)
+}
+
+// enumDescriptions returns the x-enumDescriptions extension listing the comments of the values of the enum
+// in the order of its enum, so that Swagger UI and the other consumers show the meaning of each value. An
+// enum whose values have no comments gets none.
+func (g *OpenAPIGenerator) enumDescriptions(enumDesc *thrift_reflection.EnumDescriptor) []*openapi.NamedAny {
+	descriptions := make([]string, 0, len(enumDesc.GetValues()))
+	commented := false
+	for _, v := range enumDesc.GetValues() {
+		description := g.filterCommentString(v.Comments)
+		commented = commented || description != ""
+		descriptions = append(descriptions, strconv.Quote(description))
+	}
+	if !commented {
+		return nil
+	}
+	return []*openapi.NamedAny{{
+		Name:  consts.ExtensionEnumDescriptions,
+		Value: &openapi.Any{Yaml: "[" + strings.Join(descriptions, ", ") + "]"},
+	}}
+}
+
+// isDeprecated reports whether the annotations mark a function or field as deprecated.
+func (g *OpenAPIGenerator) isDeprecated(annotations map[string][]string) bool {
+	if g.args.DeprecatedAnnotation != "" {
+		return utils.IsAnnotationEnabled(annotations, g.args.DeprecatedAnnotation)
+	}
+	return utils.IsAnnotationEnabled(annotations, consts.ApiDeprecated, consts.Deprecated)
+}
+
+// accessMode reports whether the openapi.read_only or openapi.write_only annotation of a field marks it as
+// returned by the server only, e.g. an ID assigned by the server, or as sent by the client only, e.g. a password.
+// A field marked with both is marked with neither.
+func accessMode(field *thrift_reflection.FieldDescriptor) (readOnly, writeOnly bool) {
+	readOnly = utils.IsAnnotationEnabled(field.Annotations, consts.OpenapiReadOnly)
+	writeOnly = utils.IsAnnotationEnabled(field.Annotations, consts.OpenapiWriteOnly)
+	if readOnly && writeOnly {
+		logs.Warnf("openapi.read_only and openapi.write_only of field '%s' are ignored, a field can't be both", field.GetName())
+		return false, false
+	}
+	return readOnly, writeOnly
+}
+
+// applyFormatAnnotation sets the format of a string field from its api.format annotation, e.g. uuid,
+// email or uri, which clients use to validate the values. The annotation is ignored with a warning on
+// the fields of the other types and on the strings that already have a format, such as binary.
+func applyFormatAnnotation(fieldSchema *openapi.SchemaOrReference, field *thrift_reflection.FieldDescriptor) {
+	values := field.Annotations[consts.ApiFormat]
+	if len(values) == 0 || values[0] == "" || fieldSchema == nil || !fieldSchema.IsSetSchema() {
+		return
+	}
+	if fieldSchema.Schema.Type != "string" || fieldSchema.Schema.Format != "" {
+		logs.Warnf("api.format of field '%s' is ignored, only a string field without a format takes one", field.GetName())
+		return
+	}
+	fieldSchema.Schema.Format = values[0]
+}
+
+// applyConstAnnotations sets the enum of a field to the elements of the list or set const named by its
+// openapi.enum_const annotation, and its example to the value of the const named by its openapi.example_const
+// annotation, e.g. ROLES, or base.ROLES for a const of an included file, so that the values declared in the
+// IDL aren't repeated in the annotations. The enum of a list or set field applies to its elements.
+func applyConstAnnotations(fieldSchema *openapi.SchemaOrReference, field *thrift_reflection.FieldDescriptor) {
+	if fieldSchema == nil || !fieldSchema.IsSetSchema() {
+		return
+	}
+	if names := field.Annotations[consts.OpenapiEnumConst]; len(names) > 0 && names[0] != "" {
+		values, err := thriftutils.ConstListYAML(field, names[0])
+		if err != nil {
+			logs.Warnf("openapi.enum_const of field '%s' is ignored: %s", field.GetName(), err)
+		} else {
+			enum := make([]*openapi.Any, 0, len(values))
+			for _, value := range values {
+				enum = append(enum, &openapi.Any{Yaml: value})
+			}
+			schema := fieldSchema.Schema
+			if schema.Items != nil && len(schema.Items.SchemaOrReference) == 1 && schema.Items.SchemaOrReference[0].IsSetSchema() {
+				schema = schema.Items.SchemaOrReference[0].Schema
+			}
+			schema.Enum = enum
+			schema.SpecificationExtension = removeExtension(schema.SpecificationExtension, consts.ExtensionEnumDescriptions)
+		}
+	}
+	if names := field.Annotations[consts.OpenapiExampleConst]; len(names) > 0 && names[0] != "" {
+		value, err := thriftutils.ConstYAML(field, names[0])
+		if err != nil {
+			logs.Warnf("openapi.example_const of field '%s' is ignored: %s", field.GetName(), err)
+		} else {
+			fieldSchema.Schema.Example = &openapi.Any{Yaml: value}
+		}
+	}
+}
+
+// The api.vd expressions translated by applyValidateAnnotation, compiled once for all the fields.
+var (
+	vdCompareRe = regexp.MustCompile(consts.VdComparePatternRegexp)
+	vdLenRe     = regexp.MustCompile(consts.VdLenPatternRegexp)
+	vdRegexpRe  = regexp.MustCompile(consts.VdRegexpPatternRegexp)
+)
+
+// applyValidateAnnotation translates the common api.vd expressions of a field into schema constraints.
+// Expressions that can't be represented, such as those joined with `||`, are ignored.
+func applyValidateAnnotation(fieldSchema *openapi.SchemaOrReference, annotations map[string][]string) {
+	if fieldSchema == nil || !fieldSchema.IsSetSchema() {
+		return
+	}
+	schema := fieldSchema.Schema
+
+	for _, vd := range annotations[consts.ApiVd] {
+		vd = strings.TrimSuffix(strings.TrimSpace(vd), ";")
+		if strings.Contains(vd, "||") {
+			continue
+		}
+		for _, expr := range strings.Split(vd, "&&") {
+			expr = strings.TrimSpace(expr)
+			for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+				expr = strings.TrimSpace(expr[1 : len(expr)-1])
+			}
+
+			if match := vdCompareRe.FindStringSubmatch(expr); match != nil {
+				n, err := strconv.ParseFloat(match[2], 64)
+				if err != nil {
+					continue
+				}
+				setRange(schema, match[1], n)
+			} else if match := vdLenRe.FindStringSubmatch(expr); match != nil {
+				n, err := strconv.ParseInt(match[2], 10, 64)
+				if err != nil {
+					continue
+				}
+				setLength(schema, match[1], n)
+			} else if match := vdRegexpRe.FindStringSubmatch(expr); match != nil {
+				// Backslashes are escaped in the annotation as they are in the generated struct tag
+				schema.Pattern = strings.ReplaceAll(match[1], `\\`, `\`)
+			}
+		}
+	}
+}
+
+// setRange applies a `$ op n` comparison to the minimum and maximum of a numeric schema. The bounds are
+// pointers, so that a zero bound is emitted as well.
+func setRange(schema *openapi.Schema, op string, n float64) {
+	if schema.Type != "integer" && schema.Type != "number" {
+		return
+	}
+	// Strict bounds of integers are turned into inclusive ones
+	if schema.Type == "integer" && n == float64(int64(n)) {
+		switch op {
+		case ">":
+			op, n = ">=", n+1
+		case "<":
+			op, n = "<=", n-1
+		}
+	}
+	minimum, maximum := n, n
+	switch op {
+	case ">=":
+		schema.Minimum, schema.ExclusiveMinimum = &minimum, false
+	case ">":
+		schema.Minimum, schema.ExclusiveMinimum = &minimum, true
+	case "<=":
+		schema.Maximum, schema.ExclusiveMaximum = &maximum, false
+	case "<":
+		schema.Maximum, schema.ExclusiveMaximum = &maximum, true
+	case "==":
+		schema.Minimum, schema.Maximum = &minimum, &maximum
+		schema.ExclusiveMinimum, schema.ExclusiveMaximum = false, false
+	}
+}
+
+// setLength applies a `len($) op n` comparison to the length constraints matching the schema type.
+func setLength(schema *openapi.Schema, op string, n int64) {
+	switch op {
+	case ">":
+		op, n = ">=", n+1
+	case "<":
+		op, n = "<=", n-1
+	}
+	if n < 0 {
+		return
+	}
+	minLength, maxLength := &schema.MinLength, &schema.MaxLength
+	switch schema.Type {
+	case "array":
+		minLength, maxLength = &schema.MinItems, &schema.MaxItems
+	case consts.SchemaObjectType:
+		minLength, maxLength = &schema.MinProperties, &schema.MaxProperties
+	}
+	minimum, maximum := n, n
+	switch op {
+	case ">=":
+		*minLength = &minimum
+	case "<=":
+		*maxLength = &maximum
+	case "==":
+		*minLength, *maxLength = &minimum, &maximum
+	}
+}
+
+// getOperationID returns the operation ID of the method of the service formatted by the operation ID template.
+func (g *OpenAPIGenerator) getOperationID(service, method string) string {
+	operationID, err := common.FormatOperationID(g.operationIDTemplate, service, method)
+	if err != nil {
+		logs.Errorf("Error formatting operation id of '%s': %s", method, err)
+		return service + "_" + method
+	}
+	return operationID
+}
+
 // filterCommentString removes linter rules from comments.
+// With PreserveMarkdown only the comment markers are removed, so that the markdown keeps its layout.
 func (g *OpenAPIGenerator) filterCommentString(str string) string {
+	if g.args.PreserveMarkdown {
+		return common.MarkdownComment(str)
+	}
 	var comments []string
 	matches := regexp.MustCompile(consts.CommentPatternRegexp).FindAllStringSubmatch(str, -1)
 
@@ -830,8 +1915,161 @@ func (g *OpenAPIGenerator) filterCommentString(str string) string {
 	return strings.Join(comments, "\n")
 }
 
+// indexOf returns the index of the element in the slice, or -1 if it's absent.
+func indexOf(s []string, e string) int {
+	for i, a := range s {
+		if a == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// nestedStructDescriptor returns the struct held by a field of the type, unwrapping the elements
+// of lists and sets and the values of maps.
+func nestedStructDescriptor(fieldType *thrift_reflection.TypeDescriptor) *thrift_reflection.StructDescriptor {
+	for fieldType.IsMap() || fieldType.IsList() {
+		fieldType = fieldType.GetValueType()
+	}
+	if fieldType.IsException() {
+		exceptionDesc, _ := fieldType.GetExceptionDescriptor()
+		return exceptionDesc
+	}
+	if !fieldType.IsStruct() {
+		return nil
+	}
+	structDesc, _ := fieldType.GetStructDescriptor()
+	return structDesc
+}
+
+// removeExtension removes the named extension, e.g. one that no longer applies to the schema.
+func removeExtension(extensions []*openapi.NamedAny, name string) []*openapi.NamedAny {
+	kept := extensions[:0]
+	for _, named := range extensions {
+		if named.Name != name {
+			kept = append(kept, named)
+		}
+	}
+	return kept
+}
+
+// hasExtensions reports whether the annotations set specification extensions with openapi.extension.
+func hasExtensions(annotations map[string][]string) bool {
+	return len(annotations[consts.OpenapiExtension]) > 0
+}
+
+// addExtensions sets the specification extensions of the openapi.extension annotations on the extensions
+// of an object, replacing the extensions of the same name, so that they are passed verbatim to the document.
+func addExtensions(extensions []*openapi.NamedAny, annotations map[string][]string, owner string) []*openapi.NamedAny {
+	parsed, err := common.ParseExtensions(annotations[consts.OpenapiExtension])
+	if err != nil {
+		logs.Errorf("Error parsing extension option of %s: %s", owner, err)
+		return extensions
+	}
+	for _, extension := range parsed {
+		value := &openapi.Any{Yaml: extension.Yaml}
+		replaced := false
+		for _, named := range extensions {
+			if named.Name == extension.Name {
+				named.Value, replaced = value, true
+			}
+		}
+		if !replaced {
+			extensions = append(extensions, &openapi.NamedAny{Name: extension.Name, Value: value})
+		}
+	}
+	return extensions
+}
+
+// addSunset sets the x-sunset extension of a deprecated operation to the date of the openapi.sunset
+// annotation, and documents the Sunset header of its successful responses with the date as example.
+// It fails if the date isn't an HTTP date, e.g. `Wed, 31 Dec 2025 23:59:59 GMT`.
+func addSunset(op *openapi.Operation, annotations map[string][]string, owner string) error {
+	values := annotations[consts.OpenapiSunset]
+	if len(values) == 0 || values[0] == "" {
+		return nil
+	}
+	if !op.Deprecated {
+		logs.Warnf("openapi.sunset of %s is ignored, only a deprecated function takes one", owner)
+		return nil
+	}
+	date := &openapi.Any{Yaml: strconv.Quote(values[0])}
+	if _, err := common.ParseSunset(date.Yaml); err != nil {
+		return fmt.Errorf("openapi.sunset of %s: %w", owner, err)
+	}
+	replaced := false
+	for _, named := range op.SpecificationExtension {
+		if named.Name == consts.ExtensionSunset {
+			named.Value, replaced = date, true
+		}
+	}
+	if !replaced {
+		op.SpecificationExtension = append(op.SpecificationExtension, &openapi.NamedAny{Name: consts.ExtensionSunset, Value: date})
+	}
+	if op.Responses == nil {
+		return nil
+	}
+	for _, response := range op.Responses.ResponseOrReference {
+		if !strings.HasPrefix(response.Name, "2") || response.Value == nil || response.Value.Response == nil {
+			continue
+		}
+		r := response.Value.Response
+		if r.Headers == nil {
+			r.Headers = &openapi.HeadersOrReferences{}
+		}
+		if hasHeader(r.Headers, consts.HeaderSunset) {
+			continue
+		}
+		r.Headers.AdditionalProperties = append(r.Headers.AdditionalProperties, &openapi.NamedHeaderOrReference{
+			Name: consts.HeaderSunset,
+			Value: &openapi.HeaderOrReference{
+				Header: &openapi.Header{
+					Description: consts.SunsetHeaderDesc,
+					Schema:      &openapi.SchemaOrReference{Schema: &openapi.Schema{Type: "string"}},
+					Example:     date,
+				},
+			},
+		})
+	}
+	return nil
+}
+
+// hasHeader reports whether the headers contain one of the name, which is case-insensitive.
+func hasHeader(headers *openapi.HeadersOrReferences, name string) bool {
+	for _, header := range headers.AdditionalProperties {
+		if strings.EqualFold(header.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// closeSchema disallows the properties not declared by the object schema when the ClosedSchemas
+// argument is set, unless the openapi.schema annotation already sets additionalProperties.
+func (g *OpenAPIGenerator) closeSchema(schema *openapi.Schema) {
+	if g.args.ClosedSchemas && schema.AdditionalProperties == nil {
+		schema.AdditionalProperties = &openapi.AdditionalPropertiesItem{Boolean: false}
+	}
+}
+
 func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, structs []*thrift_reflection.StructDescriptor) {
 	for _, s := range structs {
+		structKey := s.GetFilepath() + "#" + s.GetName()
+		// The struct is already being walked by an outer call, which adds its schema
+		if depth := indexOf(g.walkingStructs, structKey); depth >= 0 {
+			if depth < g.skippedDepth {
+				g.skippedDepth = depth
+			}
+			continue
+		}
+		// Structs shared by many others are walked once instead of once per path to them
+		required := len(g.requiredTypeDesc)
+		if walked, ok := g.walkedStructs[structKey]; ok && walked == required {
+			continue
+		}
+		depth := len(g.walkingStructs)
+		outerSkippedDepth := g.skippedDepth
+		g.skippedDepth = math.MaxInt32
 		var sls []*thrift_reflection.StructDescriptor
 		for _, f := range s.GetFields() {
 			fieldType := f.GetType()
@@ -839,20 +2077,30 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				logs.Errorf("Warning: field type is nil for field: %s\n", f.GetName())
 				continue
 			}
-			if fieldType.IsStruct() {
-				structDesc, _ := fieldType.GetStructDescriptor()
+			if structDesc := nestedStructDescriptor(fieldType); structDesc != nil {
 				sls = append(sls, structDesc)
 			}
 		}
 		if len(sls) > 0 {
+			g.walkingStructs = append(g.walkingStructs, structKey)
 			g.addSchemasForStructsToDocument(d, sls)
+			g.walkingStructs = g.walkingStructs[:len(g.walkingStructs)-1]
+		}
+		// The walk depends on the outer structs when it skipped one of them, so it can't be reused elsewhere
+		reusable := g.skippedDepth >= depth
+		if outerSkippedDepth < g.skippedDepth {
+			g.skippedDepth = outerSkippedDepth
 		}
 
-		schemaName := s.GetName()
+		schemaName := g.getSchemaName(s)
 
 		// Only generate this if we need it and haven't already generated it.
 		if !common.Contains(g.requiredSchemas, schemaName) ||
+			!g.claimSchemaName(schemaName, "struct "+s.GetName()) ||
 			common.Contains(g.generatedSchemas, schemaName) {
+			if reusable && len(g.requiredTypeDesc) == required {
+				g.walkedStructs[structKey] = required
+			}
 			continue
 		}
 
@@ -872,8 +2120,23 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				continue
 			}
 
+			deprecated := g.isDeprecated(field.Annotations)
+			readOnly, writeOnly := accessMode(field)
+			// A $ref can't carry siblings, so wrap it with `allOf` to mark it deprecated, read-only or write-only or extend it
+			if (deprecated || readOnly || writeOnly || hasExtensions(field.Annotations)) && fieldSchema.IsSetReference() {
+				fieldSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{
+					AllOf: []*openapi.SchemaOrReference{fieldSchema},
+				}}
+			}
+
 			if fieldSchema.IsSetSchema() {
 				fieldSchema.Schema.Description = description
+				fieldSchema.Schema.Deprecated = deprecated
+				fieldSchema.Schema.ReadOnly = readOnly
+				fieldSchema.Schema.WriteOnly = writeOnly
+				applyValidateAnnotation(fieldSchema, field.Annotations)
+				applyFormatAnnotation(fieldSchema, field)
+				applyConstAnnotations(fieldSchema, field)
 				newFieldSchema := &openapi.Schema{}
 				err := utils.ParseFieldOption(field, consts.OpenapiProperty, &newFieldSchema)
 				if err != nil {
@@ -883,7 +2146,9 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				if err != nil {
 					logs.Errorf("Error merging field option: %s", err)
 				}
+				fieldSchema.Schema.SpecificationExtension = addExtensions(fieldSchema.Schema.SpecificationExtension, field.Annotations, "field '"+field.GetName()+"'")
 			}
+			g.synthesizeExample(fieldSchema)
 
 			extName := field.GetName()
 			options := []string{consts.ApiHeader, consts.ApiBody, consts.ApiForm, consts.ApiRawBody}
@@ -919,6 +2184,8 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				logs.Errorf("Error merging struct option: %s", err)
 			}
 		}
+		schema.SpecificationExtension = addExtensions(schema.SpecificationExtension, s.Annotations, "struct '"+s.GetName()+"'")
+		g.closeSchema(schema)
 
 		// Add the schema to the components.schema list.
 		g.addSchemaToDocument(d, &openapi.NamedSchemaOrReference{
@@ -930,6 +2197,36 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 	}
 }
 
+// withDefault returns the value set by the plugin argument, or the default value if it is not set.
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// claimSchemaName records the origin of the schema name, and reports whether the name is not
+// already generated from another origin, e.g. a struct named after the body schema of another struct.
+// The first collision is kept in schemaNameErr to fail the document.
+func (g *OpenAPIGenerator) claimSchemaName(name, origin string) bool {
+	if claimed, ok := g.schemaOrigins[name]; ok && claimed != origin {
+		if g.schemaNameErr == nil {
+			g.schemaNameErr = fmt.Errorf("schema name %s of the %s collides with the %s, set another suffix with the BodySchemaSuffix, FormSchemaSuffix or RawBodySchemaSuffix argument, or another %s", name, origin, claimed, consts.OpenapiSchemaName)
+		}
+		return false
+	}
+	g.schemaOrigins[name] = origin
+	return true
+}
+
+// addDerivedSchemaToDocument adds the body, form or raw body schema derived from a struct to the
+// document, unless its name collides with a schema of another origin, which fails the document.
+func (g *OpenAPIGenerator) addDerivedSchemaToDocument(d *openapi.Document, schema *openapi.NamedSchemaOrReference, origin string) {
+	if g.claimSchemaName(schema.Name, origin) {
+		g.addSchemaToDocument(d, schema)
+	}
+}
+
 // addSchemaToDocument adds the schema to the document if required
 func (g *OpenAPIGenerator) addSchemaToDocument(d *openapi.Document, schema *openapi.NamedSchemaOrReference) {
 	if common.Contains(g.generatedSchemas, schema.Name) {
@@ -939,9 +2236,21 @@ func (g *OpenAPIGenerator) addSchemaToDocument(d *openapi.Document, schema *open
 	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, schema)
 }
 
-func (g *OpenAPIGenerator) addOperationToDocument(d *openapi.Document, op *openapi.Operation, path, methodName string) {
+// getPathItemOperations returns the operations set on the path item.
+func getPathItemOperations(item *openapi.PathItem) []*openapi.Operation {
+	var operations []*openapi.Operation
+	for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Delete, item.Patch, item.Options, item.Head} {
+		if op != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// addOperationToPaths sets the operation on the method of the path item, creating the path item if needed.
+func addOperationToPaths(paths *openapi.Paths, op *openapi.Operation, path, methodName string) {
 	var selectedPathItem *openapi.NamedPathItem
-	for _, namedPathItem := range d.Paths.Path {
+	for _, namedPathItem := range paths.Path {
 		if namedPathItem.Name == path {
 			selectedPathItem = namedPathItem
 			break
@@ -950,29 +2259,128 @@ func (g *OpenAPIGenerator) addOperationToDocument(d *openapi.Document, op *opena
 	// If we get here, we need to create a path item.
 	if selectedPathItem == nil {
 		selectedPathItem = &openapi.NamedPathItem{Name: path, Value: &openapi.PathItem{}}
-		d.Paths.Path = append(d.Paths.Path, selectedPathItem)
+		paths.Path = append(paths.Path, selectedPathItem)
 	}
-	// Set the operation on the specified method.
+	setPathItemOperation(selectedPathItem.Value, op, methodName)
+}
+
+// setPathItemOperation sets the operation on the specified method of the path item.
+func setPathItemOperation(item *openapi.PathItem, op *openapi.Operation, methodName string) {
 	switch methodName {
 	case consts.HttpMethodGet:
-		selectedPathItem.Value.Get = op
+		item.Get = op
 	case consts.HttpMethodPost:
-		selectedPathItem.Value.Post = op
+		item.Post = op
 	case consts.HttpMethodPut:
-		selectedPathItem.Value.Put = op
+		item.Put = op
 	case consts.HttpMethodDelete:
-		selectedPathItem.Value.Delete = op
+		item.Delete = op
 	case consts.HttpMethodPatch:
-		selectedPathItem.Value.Patch = op
+		item.Patch = op
 	case consts.HttpMethodOptions:
-		selectedPathItem.Value.Options = op
+		item.Options = op
 	case consts.HttpMethodHead:
-		selectedPathItem.Value.Head = op
+		item.Head = op
+	}
+}
+
+// getSchemaName returns the component schema name of the struct, see newSchemaNames.
+func (g *OpenAPIGenerator) getSchemaName(desc *thrift_reflection.StructDescriptor) string {
+	if name, ok := g.schemaNames[desc.GetFilepath()+"#"+desc.GetName()]; ok {
+		return name
+	}
+	// The openapi.schema_name annotation renames the schema and the references to it, e.g. to present
+	// an internal struct under a public name
+	if names := desc.Annotations[consts.OpenapiSchemaName]; len(names) > 0 && names[0] != "" {
+		return names[0]
+	}
+	return desc.GetName()
+}
+
+// newSchemaNames names the component schemas of the structs, unions and exceptions of the thrift file
+// and of the files it includes, keyed by their file path and name. The names set by the openapi.schema_name
+// annotation are claimed first, it fails if one is set twice or is the name of another struct of the thrift
+// file. The other structs are named after themselves, unless FQSchemaNaming is enabled or the name is
+// already taken by a struct of another file, then they are prefixed with the namespace of their file. The
+// structs of the main file take their names first, then those of the included files in the order of their
+// paths, so that the names don't depend on the order the structs are walked in. A prefixed name that is
+// still taken, e.g. by a struct of another file with the same namespace, is numbered.
+func newSchemaNames(ast *parser.Thrift, fileDesc *thrift_reflection.FileDescriptor, fqSchemaNaming bool) (map[string]string, error) {
+	var includes []string
+	for t := range ast.DepthFirstSearch() {
+		if t.Filename != ast.Filename {
+			includes = append(includes, t.Filename)
+		}
+	}
+	sort.Strings(includes)
+
+	type fileStruct struct {
+		path string
+		fd   *thrift_reflection.FileDescriptor
+		s    *thrift_reflection.StructDescriptor
 	}
+	var structs []fileStruct
+	gd := thrift_reflection.GetGlobalDescriptor(fileDesc)
+	for _, path := range append([]string{ast.Filename}, includes...) {
+		fd := gd.LookupFD(path)
+		if fd == nil {
+			continue
+		}
+		for _, ss := range [][]*thrift_reflection.StructDescriptor{fd.GetStructs(), fd.GetUnions(), fd.GetExceptions()} {
+			for _, s := range ss {
+				structs = append(structs, fileStruct{path: path, fd: fd, s: s})
+			}
+		}
+	}
+
+	names := make(map[string]string)
+	// taken holds the struct each name is given to
+	taken := make(map[string]string)
+	for _, fs := range structs {
+		if values := fs.s.Annotations[consts.OpenapiSchemaName]; len(values) > 0 && values[0] != "" {
+			if other, ok := taken[values[0]]; ok {
+				return nil, fmt.Errorf("%s %s of struct %s is already set on struct %s", consts.OpenapiSchemaName, values[0], fs.s.GetName(), other)
+			}
+			taken[values[0]] = fs.s.GetName()
+			names[fs.path+"#"+fs.s.GetName()] = values[0]
+		}
+	}
+	for _, fs := range structs {
+		key := fs.path + "#" + fs.s.GetName()
+		if _, ok := names[key]; ok {
+			continue
+		}
+		name := fs.s.GetName()
+		if other, ok := taken[name]; ok && fs.path == ast.Filename {
+			return nil, fmt.Errorf("%s %s of struct %s is the name of another struct, set another one", consts.OpenapiSchemaName, name, other)
+		}
+		if fqSchemaNaming || taken[name] != "" {
+			name = namespacedSchemaName(fs.fd, fs.s.GetName())
+		}
+		for i := 2; taken[name] != ""; i++ {
+			name = fmt.Sprintf("%s%d", namespacedSchemaName(fs.fd, fs.s.GetName()), i)
+		}
+		taken[name] = fs.s.GetName()
+		names[key] = name
+	}
+	return names, nil
+}
+
+// namespacedSchemaName prefixes the name with the go namespace of the file, or with the file name if
+// the file has no namespace.
+func namespacedSchemaName(fd *thrift_reflection.FileDescriptor, name string) string {
+	namespace := fd.Namespaces["go"]
+	if namespace == "" {
+		namespace = fd.Namespaces["*"]
+	}
+	if namespace == "" {
+		namespace = strings.TrimSuffix(filepath.Base(fd.Filepath), filepath.Ext(fd.Filepath))
+	}
+	return strings.ReplaceAll(namespace, "/", ".") + "." + name
 }
 
 func (g *OpenAPIGenerator) schemaReferenceForMessage(message *thrift_reflection.StructDescriptor) string {
-	schemaName := message.GetName()
+	schemaName := g.getSchemaName(message)
 	if !common.Contains(g.requiredSchemas, schemaName) {
 		g.requiredSchemas = append(g.requiredSchemas, schemaName)
 		g.requiredTypeDesc = append(g.requiredTypeDesc, message)
@@ -1024,6 +2432,10 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 			return nil
 		}
 		kindSchema = g.schemaOrReferenceForField(typedefDesc.Type)
+		// Keep the alias name, a $ref can't carry a title
+		if kindSchema != nil && kindSchema.Schema != nil {
+			kindSchema.Schema.Title = typedefDesc.GetAlias()
+		}
 
 	case fieldType.IsEnum():
 		enumDesc, err := fieldType.GetEnumDescriptor()
@@ -1032,12 +2444,21 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 			return nil
 		}
 		kindSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{}}
-		kindSchema.Schema.Type = "string"
-		kindSchema.Schema.Format = "enum"
 		kindSchema.Schema.Enum = make([]*openapi.Any, 0, len(enumDesc.GetValues()))
-		for _, v := range enumDesc.GetValues() {
-			kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: v.GetName()})
+		if g.args.EnumType == consts.EnumTypeInteger {
+			kindSchema.Schema.Type = "integer"
+			kindSchema.Schema.Format = "int32"
+			for _, v := range enumDesc.GetValues() {
+				kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: strconv.FormatInt(v.GetValue(), 10)})
+			}
+		} else {
+			kindSchema.Schema.Type = "string"
+			kindSchema.Schema.Format = "enum"
+			for _, v := range enumDesc.GetValues() {
+				kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: v.GetName()})
+			}
 		}
+		kindSchema.Schema.SpecificationExtension = g.enumDescriptions(enumDesc)
 
 	case fieldType.IsUnion():
 		unionDesc, err := fieldType.GetUnionDescriptor()
@@ -1053,7 +2474,15 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 		}
 
 	case fieldType.IsException():
-		logs.Errorf("Error: exception type not supported: %s for field", fieldType.GetName())
+		exceptionDesc, err := fieldType.GetExceptionDescriptor()
+		if err != nil {
+			logs.Errorf("Error getting exception descriptor: %s", err)
+			return nil
+		}
+		ref := g.schemaReferenceForMessage(exceptionDesc)
+		kindSchema = &openapi.SchemaOrReference{
+			Reference: &openapi.Reference{Xref: ref},
+		}
 
 	default:
 		kindSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{}}
@@ -1097,5 +2526,5 @@ var HttpMethodAnnotations = map[string]string{
 	consts.ApiDelete:  "DELETE",
 	consts.ApiOptions: "OPTIONS",
 	consts.ApiHEAD:    "HEAD",
-	consts.ApiAny:     "ANY",
+	consts.ApiAny:     consts.HttpMethodAny,
 }
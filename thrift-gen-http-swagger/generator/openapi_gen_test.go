@@ -0,0 +1,398 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/hertz-contrib/swagger-generate/common/consts"
+	openapi "github.com/hertz-contrib/swagger-generate/idl/thrift"
+	"github.com/hertz-contrib/swagger-generate/thrift-gen-http-swagger/args"
+	"gopkg.in/yaml.v3"
+)
+
+// parseThrift parses a thrift file under testdata with its includes, openapi.thrift is included
+// from the idl of the repository.
+func parseThrift(t *testing.T, file string) *parser.Thrift {
+	t.Helper()
+	includeDirs := []string{filepath.Join("..", "..", "idl", "thrift")}
+	ast, err := parser.ParseFile(filepath.Join("testdata", file), includeDirs, true)
+	if err != nil {
+		t.Fatalf("parse %s: %v", file, err)
+	}
+	return ast
+}
+
+// generateDocument builds the openapi.yaml of a thrift file under testdata with the plugin arguments
+// and decodes it.
+func generateDocument(t *testing.T, file string, arguments *args.Arguments) map[string]interface{} {
+	t.Helper()
+	g, err := NewOpenAPIGenerator(parseThrift(t, file), arguments)
+	if err != nil {
+		t.Fatalf("create the generator of %s: %v", file, err)
+	}
+	for _, f := range g.BuildDocument() {
+		if filepath.Base(f.GetName()) != consts.DefaultOutputYamlFile {
+			continue
+		}
+		var d map[string]interface{}
+		if err := yaml.Unmarshal([]byte(f.Content), &d); err != nil {
+			t.Fatalf("decode the document of %s: %v", file, err)
+		}
+		return d
+	}
+	t.Fatalf("no %s generated for %s", consts.DefaultOutputYamlFile, file)
+	return nil
+}
+
+// lookup returns the value at the keys in the decoded document, failing the test if there is none.
+func lookup(t *testing.T, value interface{}, keys ...string) interface{} {
+	t.Helper()
+	for i, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s is not a mapping", strings.Join(keys[:i], "."))
+		}
+		if value, ok = m[key]; !ok {
+			t.Fatalf("%s not found", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return value
+}
+
+// schemaRef returns the name of the component schema referenced by the schema.
+func schemaRef(t *testing.T, schema interface{}) string {
+	t.Helper()
+	ref, ok := lookup(t, schema, "$ref").(string)
+	if !ok {
+		t.Fatalf("%v is not a reference", schema)
+	}
+	return strings.TrimPrefix(ref, consts.ComponentSchemaPrefix)
+}
+
+// assertValues checks the values of the keys of the decoded mapping.
+func assertValues(t *testing.T, what string, value interface{}, want map[string]interface{}) {
+	t.Helper()
+	for key, w := range want {
+		if got := lookup(t, value, key); !reflect.DeepEqual(got, w) {
+			t.Errorf("%s %s = %v, want %v", what, key, got, w)
+		}
+	}
+}
+
+func TestCollidingStructNames(t *testing.T) {
+	for _, fq := range []bool{false, true} {
+		d := generateDocument(t, "collision/main.thrift", &args.Arguments{FQSchemaNaming: fq})
+		schemas := lookup(t, d, "components", "schemas")
+		// The names are given in the order of the file paths, not of the fields, and c.thrift shares
+		// the namespace of b.thrift, so its prefixed name is numbered
+		bodyName, want := "FooReqBody", map[string]string{"first": "Foo", "second": "b.Foo", "third": "b.Foo2"}
+		if fq {
+			bodyName, want["first"] = "collision.FooReqBody", "a.Foo"
+		}
+		for field, property := range map[string]string{"first": "x", "second": "y", "third": "z"} {
+			name := schemaRef(t, lookup(t, schemas, bodyName, "properties", field))
+			if name != want[field] {
+				t.Errorf("FQSchemaNaming=%v: %s references %s, want %s", fq, field, name, want[field])
+			}
+			// The schemas are told apart by their fields, x of a.Foo, y of b.Foo and z of c.Foo
+			lookup(t, schemas, name, "properties", property)
+		}
+	}
+}
+
+func TestSchemaNameAnnotation(t *testing.T) {
+	d := generateDocument(t, "schemaname/main.thrift", &args.Arguments{})
+	schemas := lookup(t, d, "components", "schemas")
+	// The name set by openapi.schema_name is claimed before the names of the included structs
+	for field, want := range map[string]string{"internal": "Foo", "foo": "inc.Foo"} {
+		if name := schemaRef(t, lookup(t, schemas, "ReqBody", "properties", field)); name != want {
+			t.Errorf("%s references %s, want %s", field, name, want)
+		}
+	}
+	lookup(t, schemas, "Foo", "properties", "x")
+	lookup(t, schemas, "inc.Foo", "properties", "y")
+
+	for file, what := range map[string]string{
+		"schemaname/twice.thrift": "openapi.schema_name User set on two structs",
+		"schemaname/taken.thrift": "openapi.schema_name User of another struct",
+		"schemaname/body.thrift":  "openapi.schema_name FooBody of the body schema of Foo",
+	} {
+		if _, err := GenerateFromThriftAST(parseThrift(t, file), &args.Arguments{}); err == nil {
+			t.Errorf("%s: no error", what)
+		}
+	}
+}
+
+func TestStructsNestedInMapsAndLists(t *testing.T) {
+	d := generateDocument(t, "nested/main.thrift", &args.Arguments{})
+	schemas := lookup(t, d, "components", "schemas")
+	if name := schemaRef(t, lookup(t, schemas, "Holder", "properties", "foos", "additionalProperties")); name != "Foo" {
+		t.Errorf("foos additionalProperties reference %s, want Foo", name)
+	}
+	if name := schemaRef(t, lookup(t, schemas, "Holder", "properties", "bars", "items")); name != "Bar" {
+		t.Errorf("bars items reference %s, want Bar", name)
+	}
+	lookup(t, schemas, "Foo", "properties", "name")
+	lookup(t, schemas, "Bar", "properties", "count")
+
+	// The pre-walk of the nested structs reaches Foo through the map value and Bar through the list element
+	g, err := NewOpenAPIGenerator(parseThrift(t, "nested/main.thrift"), &args.Arguments{})
+	if err != nil {
+		t.Fatalf("create the generator: %v", err)
+	}
+	want := map[string]string{"foos": "Foo", "bars": "Bar"}
+	for _, f := range g.fileDesc.GetStructDescriptor("Holder").GetFields() {
+		w, ok := want[f.GetName()]
+		if !ok {
+			continue
+		}
+		if s := nestedStructDescriptor(f.GetType()); s == nil || s.GetName() != w {
+			t.Errorf("nested struct of %s = %v, want %s", f.GetName(), s, w)
+		}
+	}
+}
+
+// parameterSchema returns the schema of the parameter of the operation in the decoded document.
+func parameterSchema(t *testing.T, d map[string]interface{}, path, method, name string) interface{} {
+	t.Helper()
+	parameters, _ := lookup(t, d, "paths", path, method, "parameters").([]interface{})
+	for _, p := range parameters {
+		if lookup(t, p, "name") == name {
+			return lookup(t, p, "schema")
+		}
+	}
+	t.Fatalf("parameter %s of %s %s not found", name, method, path)
+	return nil
+}
+
+func TestParameterSchemaPrecedence(t *testing.T) {
+	d := generateDocument(t, "parameter/main.thrift", &args.Arguments{})
+	// The type comes from the field, min_length and description from openapi.property, and title and
+	// max_length from openapi.parameter, whatever the order of the annotations. Each location sets
+	// its own values, so a schema shared between the parameters would show the values of another one.
+	for _, tt := range []struct {
+		path, name, title string
+		maxLength         int
+	}{
+		{"/item", "name", "query", 20},
+		{"/item/{name}", "name", "path", 10},
+		{"/item/header", "X-Name", "header", 30},
+	} {
+		assertValues(t, tt.path+" "+tt.name, parameterSchema(t, d, tt.path, "get", tt.name), map[string]interface{}{
+			"type":        "string",
+			"minLength":   1,
+			"description": "property",
+			"title":       tt.title,
+			"maxLength":   tt.maxLength,
+		})
+	}
+
+	// The component schema of the same field only takes openapi.property
+	assertValues(t, "Item.name", lookup(t, d, "components", "schemas", "Item", "properties", "name"), map[string]interface{}{
+		"title":     "property",
+		"minLength": 1,
+		"maxLength": 100,
+	})
+}
+
+func TestPublicOperationSecurity(t *testing.T) {
+	d := generateDocument(t, "security/main.thrift", &args.Arguments{})
+	want := []interface{}{map[string]interface{}{"bearer_auth": []interface{}{}}}
+	if got := lookup(t, d, "paths", "/hello", "get", "security"); !reflect.DeepEqual(got, want) {
+		t.Errorf("security of /hello = %v, want %v", got, want)
+	}
+	// openapi.security = "" is emitted as an empty list, so the operation doesn't inherit any security
+	if got := lookup(t, d, "paths", "/public", "get", "security"); !reflect.DeepEqual(got, []interface{}{}) {
+		t.Errorf("security of /public = %v, want []", got)
+	}
+}
+
+func TestApplyValidateAnnotation(t *testing.T) {
+	for _, tt := range []struct {
+		schemaType string
+		vd         string
+		want       string
+	}{
+		{"integer", "$ > 0", "minimum: 1\ntype: integer\n"},
+		{"integer", "$ >= 0", "minimum: 0\ntype: integer\n"},
+		{"integer", "$ < 0", "maximum: -1\ntype: integer\n"},
+		{"integer", "$ <= 0", "maximum: 0\ntype: integer\n"},
+		{"integer", "$ > -5 && $ < 5", "maximum: 4\nminimum: -4\ntype: integer\n"},
+		{"integer", "$ == 0", "maximum: 0\nminimum: 0\ntype: integer\n"},
+		{"number", "$ > 0", "minimum: 0\nexclusiveMinimum: true\ntype: number\n"},
+		{"number", "$ < 0", "maximum: 0\nexclusiveMaximum: true\ntype: number\n"},
+		{"number", "$ >= -2.5 && $ <= 2.5", "maximum: 2.5\nminimum: -2.5\ntype: number\n"},
+		{"number", "$ > 0.5", "minimum: 0.5\nexclusiveMinimum: true\ntype: number\n"},
+		{"string", "$ > 0", "type: string\n"},
+		{"string", "len($) > 0", "minLength: 1\ntype: string\n"},
+		{"string", "len($) >= 0", "minLength: 0\ntype: string\n"},
+		{"string", "len($) <= 0", "maxLength: 0\ntype: string\n"},
+		{"string", "len($) < 0", "type: string\n"},
+		{"string", "len($) < 3", "maxLength: 2\ntype: string\n"},
+		{"string", "len($) == 4", "maxLength: 4\nminLength: 4\ntype: string\n"},
+		{"array", "len($) >= 1 && len($) <= 10", "maxItems: 10\nminItems: 1\ntype: array\n"},
+		{"object", "len($) <= 0", "maxProperties: 0\ntype: object\n"},
+		{"integer", "$ > 0 || $ < -10", "type: integer\n"},
+		{"integer", "($ >= 1);", "minimum: 1\ntype: integer\n"},
+		{"string", `regexp('^\\d+$')`, "pattern: ^\\d+$\ntype: string\n"},
+	} {
+		fieldSchema := &openapi.SchemaOrReference{Schema: &openapi.Schema{Type: tt.schemaType}}
+		applyValidateAnnotation(fieldSchema, map[string][]string{consts.ApiVd: {tt.vd}})
+		got, err := yaml.Marshal(fieldSchema.Schema.ToRawInfo())
+		if err != nil {
+			t.Fatalf("marshal the schema of %s: %v", tt.vd, err)
+		}
+		// Whole bounds are tagged as floats, which is irrelevant to the values compared
+		if g := strings.ReplaceAll(string(got), "!!float ", ""); g != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.schemaType, tt.vd, g, tt.want)
+		}
+	}
+}
+
+func TestInvalidOperationIDTemplate(t *testing.T) {
+	for _, text := range []string{"{{.Method", "{{.Unknown}}", "{{if false}}x{{end}}"} {
+		if _, err := NewOpenAPIGenerator(parseThrift(t, "collision/main.thrift"), &args.Arguments{OperationIDTemplate: text}); err == nil {
+			t.Errorf("OperationIDTemplate %q: no error", text)
+		}
+	}
+}
+
+func TestWebhookNeedsOpenAPI31(t *testing.T) {
+	ast := parseThrift(t, "webhook/main.thrift")
+	if _, err := GenerateFromThriftAST(ast, &args.Arguments{}); err == nil {
+		t.Errorf("webhook in an openapi %s document: no error", consts.OpenAPIVersion)
+	}
+	d, err := GenerateFromThriftAST(ast, &args.Arguments{OpenAPIVersion: consts.OpenAPIVersion31})
+	if err != nil {
+		t.Fatalf("webhook in an openapi %s document: %v", consts.OpenAPIVersion31, err)
+	}
+	if d.Webhooks == nil || len(d.Webhooks.Path) != 1 || d.Webhooks.Path[0].Name != "newPet" {
+		t.Errorf("webhooks = %v, want newPet", d.Webhooks)
+	}
+}
+
+func TestDerivedSchemaNameCollision(t *testing.T) {
+	ast := parseThrift(t, "derived/main.thrift")
+	if _, err := GenerateFromThriftAST(ast, &args.Arguments{}); err == nil {
+		t.Errorf("struct FooBody next to the body schema of Foo: no error")
+	}
+	d, err := GenerateFromThriftAST(ast, &args.Arguments{BodySchemaSuffix: "Payload"})
+	if err != nil {
+		t.Fatalf("BodySchemaSuffix=Payload: %v", err)
+	}
+	names := map[string]bool{}
+	for _, schema := range d.Components.Schemas.AdditionalProperties {
+		names[schema.Name] = true
+	}
+	if !names["FooBody"] || !names["FooPayload"] {
+		t.Errorf("schemas = %v, want FooBody and FooPayload", names)
+	}
+}
+
+func TestExceptionResponses(t *testing.T) {
+	ast := parseThrift(t, "exception/main.thrift")
+	if _, err := GenerateFromThriftAST(ast, &args.Arguments{}); err == nil {
+		t.Fatalf("exceptions Internal and Unavailable both on 500: no error")
+	}
+	ast.Services[0].Functions = ast.Services[0].Functions[:1]
+	d, err := GenerateFromThriftAST(ast, &args.Arguments{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, r := range d.Paths.Path[0].Value.Get.Responses.ResponseOrReference {
+		codes = append(codes, r.Name)
+	}
+	if want := []string{"200", "400", "500", "409"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("response codes = %v, want %v", codes, want)
+	}
+}
+
+// writeNestedIDL writes a thrift file of each level up to the depth under the directory, each level
+// including the next one and declaring width structs that reference two structs of the next level, so
+// that a struct is reached by many paths. It returns the path of the top level file, which declares a
+// service with a function taking each of its structs.
+func writeNestedIDL(b *testing.B, dir string, depth, width int) string {
+	b.Helper()
+	for level := depth - 1; level >= 0; level-- {
+		var idl strings.Builder
+		fmt.Fprintf(&idl, "namespace go level%d\n\n", level)
+		if level < depth-1 {
+			fmt.Fprintf(&idl, "include \"level%d.thrift\"\n\n", level+1)
+		}
+		for i := 0; i < width; i++ {
+			fmt.Fprintf(&idl, "struct S%d {\n", i)
+			if level < depth-1 {
+				fmt.Fprintf(&idl, "    1: level%d.S%d left (api.body = \"left\")\n", level+1, i)
+				fmt.Fprintf(&idl, "    2: level%d.S%d right (api.body = \"right\")\n", level+1, (i+1)%width)
+			} else {
+				idl.WriteString("    1: string name (api.body = \"name\")\n")
+			}
+			idl.WriteString("}\n\n")
+		}
+		if level == 0 {
+			idl.WriteString("service NestedService {\n")
+			for i := 0; i < width; i++ {
+				fmt.Fprintf(&idl, "    S%d Get%d(1: S%d req) (api.post = \"/nested/%d\")\n", i, i, i, i)
+			}
+			idl.WriteString("}\n")
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("level%d.thrift", level)), []byte(idl.String()), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "level0.thrift")
+}
+
+func BenchmarkNestedStructs(b *testing.B) {
+	const depth, width = 16, 20
+	file := writeNestedIDL(b, b.TempDir(), depth, width)
+	ast, err := parser.ParseFile(file, nil, true)
+	if err != nil {
+		b.Fatalf("parse %s: %v", file, err)
+	}
+	d, err := GenerateFromThriftAST(ast, &args.Arguments{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if n := len(d.Components.Schemas.AdditionalProperties); n < depth*width {
+		b.Fatalf("%d schemas, want at least %d", n, depth*width)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = GenerateFromThriftAST(ast, &args.Arguments{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSwagger2NeedsSingleDocument(t *testing.T) {
+	ast := parseThrift(t, "collision/main.thrift")
+	if _, err := GenerateFromThriftAST(ast, &args.Arguments{Swagger2: true, OutputMode: consts.OutputModeSplit}); err == nil {
+		t.Errorf("Swagger2 in %s output mode: no error", consts.OutputModeSplit)
+	}
+	if _, err := GenerateFromThriftAST(ast, &args.Arguments{Swagger2: true}); err != nil {
+		t.Errorf("Swagger2: %v", err)
+	}
+}
@@ -31,7 +31,8 @@ import (
 )
 
 type ServerGenerator struct {
-	OutputDir string
+	OutputDir    string
+	SplitSchemas bool
 }
 
 func NewServerGenerator(ast *parser.Thrift, args *args.Arguments) (*ServerGenerator, error) {
@@ -48,7 +49,8 @@ func NewServerGenerator(ast *parser.Thrift, args *args.Arguments) (*ServerGenera
 	}
 
 	return &ServerGenerator{
-		OutputDir: outputDir,
+		OutputDir:    outputDir,
+		SplitSchemas: args.OutputMode == consts.OutputModeSplit,
 	}, nil
 }
 
@@ -0,0 +1,5 @@
+namespace go a
+
+struct Foo {
+    1: string x
+}
@@ -0,0 +1,5 @@
+namespace go b
+
+struct Foo {
+    1: i64 y
+}
@@ -0,0 +1,15 @@
+namespace go collision
+
+include "a.thrift"
+include "b.thrift"
+include "c.thrift"
+
+struct FooReq {
+    1: c.Foo third (api.body = "third")
+    2: a.Foo first (api.body = "first")
+    3: b.Foo second (api.body = "second")
+}
+
+service FooService {
+    FooReq Get(1: FooReq req) (api.post = "/foo")
+}
@@ -0,0 +1,15 @@
+namespace go derived
+
+// FooBody is named like the body schema derived from Foo
+struct FooBody {
+    1: string b
+}
+
+struct Foo {
+    1: string a (api.body = "a")
+    2: FooBody inner (api.body = "inner")
+}
+
+service FooService {
+    Foo Get(1: Foo req) (api.post = "/foo")
+}
@@ -0,0 +1,30 @@
+namespace go exception
+
+struct GetReq {
+    1: string id (api.query = "id")
+}
+
+struct GetResp {
+    1: string name (api.body = "name")
+}
+
+exception BadRequest {
+    1: string message (api.body = "message")
+}
+
+exception Internal {
+    1: string message (api.body = "message")
+}
+
+exception Unavailable {
+    1: string message (api.body = "message")
+}
+
+exception Conflict {
+    1: string message (api.body = "message")
+} (api.response_code = "409")
+
+service ExceptionService {
+    GetResp Get(1: GetReq req) throws (1: BadRequest bad, 2: Internal internal, 3: Conflict conflict) (api.get = "/get")
+    GetResp Unavailable(1: GetReq req) throws (1: BadRequest bad, 2: Internal internal, 3: Unavailable unavailable) (api.get = "/unavailable")
+}
@@ -0,0 +1,23 @@
+namespace go nested
+
+struct Foo {
+    1: string name
+}
+
+struct Bar {
+    1: i32 count
+}
+
+// Foo and Bar are only used as the values of a map and the elements of a list
+struct Holder {
+    1: map<string, Foo> foos
+    2: list<Bar> bars
+}
+
+struct HolderReq {
+    1: Holder holder (api.body = "holder")
+}
+
+service HolderService {
+    HolderReq Get(1: HolderReq req) (api.post = "/holder")
+}
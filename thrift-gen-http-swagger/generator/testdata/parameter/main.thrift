@@ -0,0 +1,40 @@
+namespace go parameter
+
+include "openapi.thrift"
+
+// The schema of name comes from its type, openapi.property and the schema of openapi.parameter
+struct Item {
+    1: string name (
+        api.query = "name",
+        openapi.property = '{title: "property", description: "property", min_length: 1, max_length: 100}',
+        openapi.parameter = '{schema: {schema: {title: "query", max_length: 20}}}'
+    )
+}
+
+// The annotations are declared in the reverse order
+struct PathItemReq {
+    1: string name (
+        api.path = "name",
+        openapi.parameter = '{schema: {schema: {title: "path", max_length: 10}}}',
+        openapi.property = '{title: "property", description: "property", min_length: 1, max_length: 100}'
+    )
+}
+
+struct HeaderItemReq {
+    1: string name (
+        api.header = "X-Name",
+        openapi.property = '{title: "property", description: "property", min_length: 1, max_length: 100}',
+        openapi.parameter = '{schema: {schema: {title: "header", max_length: 30}}}'
+    )
+}
+
+struct CreateItemReq {
+    1: Item item (api.body = "item")
+}
+
+service ItemService {
+    Item QueryItem(1: Item req) (api.get = "/item")
+    Item PathItem(1: PathItemReq req) (api.get = "/item/:name")
+    Item HeaderItem(1: HeaderItemReq req) (api.get = "/item/header")
+    Item CreateItem(1: CreateItemReq req) (api.post = "/item")
+}
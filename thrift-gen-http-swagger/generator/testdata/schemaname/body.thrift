@@ -0,0 +1,15 @@
+namespace go schemaname
+
+// Internal is presented under the name of the body schema derived from Foo
+struct Internal {
+    1: string b
+} (openapi.schema_name = "FooBody")
+
+struct Foo {
+    1: string a (api.body = "a")
+    2: Internal inner (api.body = "inner")
+}
+
+service FooService {
+    Foo Get(1: Foo req) (api.post = "/foo")
+}
@@ -0,0 +1,17 @@
+namespace go schemaname
+
+include "inc.thrift"
+
+// Internal is presented as Foo, so Foo of inc.thrift is prefixed
+struct Internal {
+    1: string x
+} (openapi.schema_name = "Foo")
+
+struct Req {
+    1: Internal internal (api.body = "internal")
+    2: inc.Foo foo (api.body = "foo")
+}
+
+service FooService {
+    Req Get(1: Req req) (api.post = "/foo")
+}
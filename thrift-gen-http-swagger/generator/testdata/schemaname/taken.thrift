@@ -0,0 +1,18 @@
+namespace go schemaname
+
+struct User {
+    1: string x
+}
+
+struct Internal {
+    1: string y
+} (openapi.schema_name = "User")
+
+struct Req {
+    1: User user (api.body = "user")
+    2: Internal internal (api.body = "internal")
+}
+
+service UserService {
+    Req Get(1: Req req) (api.post = "/user")
+}
@@ -0,0 +1,18 @@
+namespace go schemaname
+
+struct Admin {
+    1: string x
+} (openapi.schema_name = "User")
+
+struct Member {
+    1: string y
+} (openapi.schema_name = "User")
+
+struct Req {
+    1: Admin admin (api.body = "admin")
+    2: Member member (api.body = "member")
+}
+
+service UserService {
+    Req Get(1: Req req) (api.post = "/user")
+}
@@ -0,0 +1,12 @@
+namespace go security
+
+struct HelloReq {
+    1: string name (api.query = "name")
+}
+
+service HelloService {
+    HelloReq Hello(1: HelloReq req) (api.get = "/hello")
+    HelloReq Public(1: HelloReq req) (api.get = "/public", openapi.security = "")
+} (
+    openapi.security = '{"bearer_auth": {"type": "http", "scheme": "bearer"}}'
+)
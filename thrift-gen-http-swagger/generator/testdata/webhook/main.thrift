@@ -0,0 +1,9 @@
+namespace go webhook
+
+struct Pet {
+    1: string name
+}
+
+service PetService {
+    Pet NewPet(1: Pet req) (api.post = "/pets", openapi.webhook = "newPet")
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/thriftgo/plugin"
+	"github.com/hertz-contrib/swagger-generate/common/consts"
 	"github.com/hertz-contrib/swagger-generate/thrift-gen-http-swagger/args"
 	"github.com/hertz-contrib/swagger-generate/thrift-gen-http-swagger/generator"
 )
@@ -61,13 +62,28 @@ func handleRequest(req *plugin.Request) (err error) {
 
 	ast := req.GetAST()
 
-	og := generator.NewOpenAPIGenerator(ast)
-	openapiContent := og.BuildDocument(args)
+	og, err := generator.NewOpenAPIGenerator(ast, args)
+	if err != nil {
+		return err
+	}
+	openapiContent := og.BuildDocument()
+	if openapiContent == nil {
+		return errors.New("failed to build openapi document")
+	}
+	if args.OutputMode == consts.OutputModeService {
+		serviceContent := og.BuildServiceDocuments()
+		if serviceContent == nil {
+			return errors.New("failed to build openapi documents of the services")
+		}
+		openapiContent = append(openapiContent, serviceContent...)
+	}
 
 	sg, err := generator.NewServerGenerator(ast, args)
 	if err != nil {
 		return err
 	}
+	// The schemas directory is only embedded if schemas were written to it, next to openapi.yaml
+	sg.SplitSchemas = sg.SplitSchemas && len(openapiContent) > 1
 	serverContent, err := sg.Generate()
 	if err != nil {
 		return err
@@ -124,3 +124,55 @@ func GetAnnotations(input map[string][]string, targets map[string]string) map[st
 	}
 	return out
 }
+
+// IsAnnotationEnabled reports whether any of the keys is present in the annotations
+// with a value other than "false".
+func IsAnnotationEnabled(annotations map[string][]string, keys ...string) bool {
+	for _, key := range keys {
+		if values, ok := annotations[key]; ok {
+			return len(values) == 0 || values[0] != "false"
+		}
+	}
+	return false
+}
+
+// RegisterExceptionFields links the fields of the exceptions and unions of the file and of its
+// includes to the global descriptor of the file. thrift_reflection only links the fields of
+// structs, so the struct, enum and typedef types of the other fields can't be looked up.
+func RegisterExceptionFields(fd *thrift_reflection.FileDescriptor) {
+	registerExceptionFields(fd, map[string]bool{})
+}
+
+func registerExceptionFields(fd *thrift_reflection.FileDescriptor, visited map[string]bool) {
+	if fd == nil || visited[fd.GetFilepath()] {
+		return
+	}
+	visited[fd.GetFilepath()] = true
+	uuid := fd.GetExtra()[thrift_reflection.GLOBAL_UUID_EXTRA_KEY]
+	if uuid == "" {
+		return
+	}
+	for _, structs := range [][]*thrift_reflection.StructDescriptor{fd.GetExceptions(), fd.GetUnions()} {
+		for _, s := range structs {
+			for _, f := range s.GetFields() {
+				registerTypeDescriptor(f.GetType(), uuid)
+			}
+		}
+	}
+	gd := thrift_reflection.GetGlobalDescriptor(fd)
+	for _, path := range fd.GetIncludes() {
+		registerExceptionFields(gd.LookupFD(path), visited)
+	}
+}
+
+func registerTypeDescriptor(td *thrift_reflection.TypeDescriptor, uuid string) {
+	if td == nil {
+		return
+	}
+	if td.Extra == nil {
+		td.Extra = map[string]string{}
+	}
+	td.Extra[thrift_reflection.GLOBAL_UUID_EXTRA_KEY] = uuid
+	registerTypeDescriptor(td.GetKeyType(), uuid)
+	registerTypeDescriptor(td.GetValueType(), uuid)
+}
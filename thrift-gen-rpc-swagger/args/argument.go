@@ -23,9 +23,30 @@ import (
 )
 
 type Arguments struct {
-	OutputDir string
-	HertzAddr string
-	KitexAddr string
+	OutputDir            string
+	OutputMode           string
+	FileHeader           string
+	HertzAddr            string
+	KitexAddr            string
+	ProxyPrefix          string
+	RPCTimeout           string
+	MaxRetryTimes        int
+	Registry             string
+	RegistryAddr         string
+	ServiceName          string
+	TLSCertFile          string
+	TLSKeyFile           string
+	DeprecatedAnnotation string
+	EnumType             string
+	FQSchemaNaming       bool
+	InlineSchemas        bool
+	PruneUnused          bool
+	ClosedSchemas        bool
+	OperationIDTemplate  string
+	OpenAPIVersion       string
+	Validate             bool
+	PreserveMarkdown     bool
+	DescriptionFromFile  bool
 }
 
 func (a *Arguments) Unpack(args []string) error {
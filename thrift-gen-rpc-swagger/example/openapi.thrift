@@ -2,6 +2,7 @@ namespace go openapi
 
 struct _ServiceOptions {
       1:required Document document
+      2:required ExternalDocs external_docs
 }
 
 struct _StructOptions {
@@ -10,6 +11,7 @@ struct _StructOptions {
 
 struct _MethodOptions {
       1:required Operation operation
+      2:required ExternalDocs external_docs
 }
 
 struct _FieldOptions {
@@ -92,7 +94,8 @@ struct Document {
   6: list<SecurityRequirement> security,
   7: list<Tag> tags,
   8: ExternalDocs external_docs,
-  9: list<NamedAny> specification_extension
+  9: list<NamedAny> specification_extension,
+  10: Paths webhooks
 }
 
 struct Encoding {
@@ -435,18 +438,18 @@ struct Schema {
   8: bool deprecated,
   9: string title,
   10: double multiple_of,
-  11: double maximum,
+  11: optional double maximum,
   12: bool exclusive_maximum,
-  13: double minimum,
+  13: optional double minimum,
   14: bool exclusive_minimum,
-  15: i64 max_length,
-  16: i64 min_length,
+  15: optional i64 max_length,
+  16: optional i64 min_length,
   17: string pattern,
-  18: i64 max_items,
-  19: i64 min_items,
+  18: optional i64 max_items,
+  19: optional i64 min_items,
   20: bool unique_items,
-  21: i64 max_properties,
-  22: i64 min_properties,
+  21: optional i64 max_properties,
+  22: optional i64 min_properties,
   23: list<string> required,
   24: list<Any> enum,
   25: string type,
@@ -34,16 +34,21 @@
 package generator
 
 import (
+	"fmt"
+	"math"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
 	"github.com/cloudwego/thriftgo/thrift_reflection"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
+	"github.com/hertz-contrib/swagger-generate/common/thriftutils"
 	common "github.com/hertz-contrib/swagger-generate/common/utils"
 	openapi "github.com/hertz-contrib/swagger-generate/idl/thrift"
 	"github.com/hertz-contrib/swagger-generate/thrift-gen-rpc-swagger/args"
@@ -53,29 +58,82 @@ import (
 type OpenAPIGenerator struct {
 	fileDesc         *thrift_reflection.FileDescriptor
 	ast              *parser.Thrift
+	args             *args.Arguments
 	generatedSchemas []string
 	requiredSchemas  []string
 	requiredTypeDesc []*thrift_reflection.StructDescriptor
+	// schemaNames are the component schema names of the structs, keyed by their file path and name
+	schemaNames map[string]string
+	// walkingStructs are the structs whose nested structs are being added, to stop at recursive structs
+	walkingStructs []string
+	// walkedStructs holds the number of required structs when each struct was last walked without adding
+	// a schema, walking it again is a no-op until more structs are required
+	walkedStructs map[string]int
+	// skippedDepth is the lowest depth in walkingStructs of the recursive structs skipped by the current walk
+	skippedDepth int
+	// operationIDTemplate formats the operation IDs from the service and function names
+	operationIDTemplate *template.Template
+	// service restricts the document to the service of that name, all the services are documented if empty
+	service string
+	// proxyPrefix is the path prefix under which the proxy routes the functions
+	proxyPrefix string
 }
 
-// NewOpenAPIGenerator creates a new generator for a protoc plugin invocation.
-func NewOpenAPIGenerator(ast *parser.Thrift) *OpenAPIGenerator {
+// NewOpenAPIGenerator creates a new generator for a thriftgo plugin invocation. It fails if the
+// operation ID template can't be parsed.
+func NewOpenAPIGenerator(ast *parser.Thrift, args *args.Arguments) (*OpenAPIGenerator, error) {
 	_, fileDesc := thrift_reflection.RegisterAST(ast)
+	utils.RegisterExceptionFields(fileDesc)
+	operationIDTemplate, err := common.NewOperationIDTemplate(args.OperationIDTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operation id template: %s", err.Error())
+	}
+	schemaNames, err := newSchemaNames(ast, fileDesc, args.FQSchemaNaming)
+	if err != nil {
+		return nil, err
+	}
 	return &OpenAPIGenerator{
-		fileDesc:         fileDesc,
-		ast:              ast,
-		generatedSchemas: make([]string, 0),
+		fileDesc:            fileDesc,
+		ast:                 ast,
+		args:                args,
+		generatedSchemas:    make([]string, 0),
+		schemaNames:         schemaNames,
+		walkedStructs:       make(map[string]int),
+		operationIDTemplate: operationIDTemplate,
+		proxyPrefix:         common.ProxyPrefix(args.ProxyPrefix),
+	}, nil
+}
+
+// GenerateFromThriftAST builds the OpenAPI document of the services in the thrift AST without running
+// the plugin, so that tools and tests can call the generator from Go code. Nil arguments use the
+// defaults of the plugin options. The document is not written anywhere; use its YAMLValue method to
+// get the bytes of openapi.yaml, and ConvertToOpenAPI31 of the common utils for OpenAPIVersion 3.1.0.
+func GenerateFromThriftAST(ast *parser.Thrift, arguments *args.Arguments) (*openapi.Document, error) {
+	if arguments == nil {
+		arguments = &args.Arguments{}
+	}
+	g, err := NewOpenAPIGenerator(ast, arguments)
+	if err != nil {
+		return nil, err
 	}
+	return g.buildDocument()
 }
 
-func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Generated {
+// buildDocument builds an OpenAPIv3 document from the thrift AST.
+func (g *OpenAPIGenerator) buildDocument() (*openapi.Document, error) {
 	d := &openapi.Document{}
 
 	version := consts.OpenAPIVersion
+	if g.args.OpenAPIVersion != "" {
+		version = g.args.OpenAPIVersion
+	}
+	if version != consts.OpenAPIVersion && version != consts.OpenAPIVersion31 {
+		return nil, fmt.Errorf("unsupported openapi version %q, use %q or %q", version, consts.OpenAPIVersion, consts.OpenAPIVersion31)
+	}
 	d.Openapi = version
 	d.Info = &openapi.Info{
 		Title:       consts.DefaultInfoTitle + consts.PluginNameThriftRpcSwagger,
-		Description: consts.DefaultInfoDesc,
+		Description: g.defaultDescription(),
 		Version:     consts.DefaultInfoVersion,
 	}
 	d.Paths = &openapi.Paths{}
@@ -88,18 +146,22 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 	var extDocument *openapi.Document
 	err := g.getDocumentOption(&extDocument)
 	if err != nil {
-		logs.Errorf("Error getting document option: %s", err)
-		return nil
+		return nil, fmt.Errorf("failed to get document option: %w", err)
 	}
+	var declaredTags []string
 	if extDocument != nil {
 		err := common.MergeStructs(d, extDocument)
 		if err != nil {
-			logs.Errorf("Error merging document option: %s", err)
-			return nil
+			return nil, fmt.Errorf("failed to merge document option: %w", err)
+		}
+		for _, tag := range extDocument.Tags {
+			declaredTags = append(declaredTags, tag.Name)
 		}
 	}
 
-	g.addPathsToDocument(d, g.fileDesc.GetServices())
+	if err = g.addPathsToDocument(d, g.services()); err != nil {
+		return nil, err
+	}
 
 	for len(g.requiredSchemas) > 0 {
 		count := len(g.requiredSchemas)
@@ -118,6 +180,8 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		}
 	}
 
+	addOperationTagsToDocument(d)
+
 	var allServers []string
 
 	// If paths methods has servers, but they're all the same, then move servers to path level
@@ -160,13 +224,7 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		}
 	}
 
-	{
-		pairs := d.Tags
-		sort.Slice(pairs, func(i, j int) bool {
-			return pairs[i].Name < pairs[j].Name
-		})
-		d.Tags = pairs
-	}
+	sortTags(d.Tags, declaredTags)
 
 	{
 		pairs := d.Paths.Path
@@ -184,17 +242,98 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 		d.Components.Schemas.AdditionalProperties = pairs
 	}
 
-	bytes, err := d.YAMLValue("Generated with " + consts.PluginNameThriftRpcSwagger + "\n" + consts.InfoURL + consts.PluginNameThriftRpcSwagger)
+	return d, nil
+}
+
+func (g *OpenAPIGenerator) BuildDocument() []*plugin.Generated {
+	d, err := g.buildDocument()
 	if err != nil {
-		logs.Errorf("Error converting to yaml: %s", err)
+		logs.Errorf("Error building document: %s", err)
 		return nil
 	}
-	outputDir := arguments.OutputDir
+
+	outputDir := g.args.OutputDir
 	if outputDir == "" {
 		outputDir = consts.DefaultOutputDir
 	}
-	filePath := filepath.Join(outputDir, consts.DefaultOutputYamlFile)
+	comment, err := g.comment()
+	if err != nil {
+		logs.Errorf("Error reading file header: %s", err)
+		return nil
+	}
+
 	var ret []*plugin.Generated
+	var bytes []byte
+	if g.args.OutputMode == consts.OutputModeSplit {
+		if g.args.InlineSchemas {
+			logs.Warnf("InlineSchemas is ignored in %s output mode", consts.OutputModeSplit)
+		}
+		var schemas map[string][]byte
+		bytes, schemas, err = d.SplitYAMLValue(comment, consts.DefaultOutputSchemaDir)
+		if err != nil {
+			logs.Errorf("Error converting to yaml: %s", err)
+			return nil
+		}
+		if g.args.PruneUnused {
+			full, err := d.YAMLValue(comment)
+			if err != nil {
+				logs.Errorf("Error converting to yaml: %s", err)
+				return nil
+			}
+			unused, err := common.UnusedSchemas(full)
+			if err != nil {
+				logs.Errorf("Error pruning schemas: %s", err)
+				return nil
+			}
+			for _, name := range unused {
+				delete(schemas, name)
+			}
+		}
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+				if schemas[name], err = common.ConvertSchemaToOpenAPI31(schemas[name]); err != nil {
+					logs.Errorf("Error converting schema to openapi %s: %s", consts.OpenAPIVersion31, err)
+					return nil
+				}
+			}
+			schemaPath := filepath.Join(outputDir, consts.DefaultOutputSchemaDir, name+".yaml")
+			ret = append(ret, &plugin.Generated{
+				Content: string(schemas[name]),
+				Name:    &schemaPath,
+			})
+		}
+		if g.args.Validate {
+			// Split schemas are referenced by relative paths, so validate the document with them inlined.
+			full, err := d.YAMLValue(comment)
+			if err != nil {
+				logs.Errorf("Error converting to yaml: %s", err)
+				return nil
+			}
+			if err = common.ValidateOpenAPI(full); err != nil {
+				logs.Errorf("Error validating openapi document: %s", err)
+				return nil
+			}
+		}
+		// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+		if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+			if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+				logs.Errorf("Error converting to openapi %s: %s", consts.OpenAPIVersion31, err)
+				return nil
+			}
+		}
+	} else {
+		bytes, err = g.marshalDocument(d, comment)
+		if err != nil {
+			logs.Errorf("Error marshaling document: %s", err)
+			return nil
+		}
+	}
+	filePath := filepath.Join(outputDir, consts.DefaultOutputYamlFile)
 	ret = append(ret, &plugin.Generated{
 		Content: string(bytes),
 		Name:    &filePath,
@@ -203,6 +342,116 @@ func (g *OpenAPIGenerator) BuildDocument(arguments *args.Arguments) []*plugin.Ge
 	return ret
 }
 
+// BuildServiceDocuments builds a document for each service of the thrift file in the service output
+// mode, written to [service].openapi.yaml next to openapi.yaml. Each document is built by its own
+// generator, so the schemas shared by the services are repeated in the documents of each of them.
+func (g *OpenAPIGenerator) BuildServiceDocuments() []*plugin.Generated {
+	outputDir := g.args.OutputDir
+	if outputDir == "" {
+		outputDir = consts.DefaultOutputDir
+	}
+
+	comment, err := g.comment()
+	if err != nil {
+		logs.Errorf("Error reading file header: %s", err)
+		return nil
+	}
+
+	ret := make([]*plugin.Generated, 0)
+	for _, s := range g.fileDesc.GetServices() {
+		sg, err := NewOpenAPIGenerator(g.ast, g.args)
+		if err != nil {
+			logs.Errorf("Error creating generator of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		sg.service = s.GetName()
+		d, err := sg.buildDocument()
+		if err != nil {
+			logs.Errorf("Error building document of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		bytes, err := sg.marshalDocument(d, comment)
+		if err != nil {
+			logs.Errorf("Error marshaling document of service %s: %s", s.GetName(), err)
+			return nil
+		}
+		filePath := filepath.Join(outputDir, s.GetName()+"."+consts.DefaultOutputYamlFile)
+		ret = append(ret, &plugin.Generated{
+			Content: string(bytes),
+			Name:    &filePath,
+		})
+	}
+	return ret
+}
+
+// marshalDocument returns the YAML of a document written as a single file, with the schemas pruned
+// and inlined as configured, validated and converted to the configured OpenAPI version.
+func (g *OpenAPIGenerator) marshalDocument(d *openapi.Document, comment string) ([]byte, error) {
+	bytes, err := d.YAMLValue(comment)
+	if err != nil {
+		return nil, err
+	}
+	if g.args.PruneUnused {
+		if bytes, err = common.PruneSchemas(bytes); err != nil {
+			return nil, fmt.Errorf("failed to prune schemas: %w", err)
+		}
+	}
+	if g.args.InlineSchemas {
+		if bytes, err = common.InlineSchemas(bytes); err != nil {
+			return nil, fmt.Errorf("failed to inline schemas: %w", err)
+		}
+	}
+	if g.args.Validate {
+		if err = common.ValidateOpenAPI(bytes); err != nil {
+			return nil, fmt.Errorf("failed to validate openapi document: %w", err)
+		}
+	}
+	// The document is validated against OpenAPI 3.0 before its schemas are converted to 3.1.
+	if g.args.OpenAPIVersion == consts.OpenAPIVersion31 {
+		if bytes, err = common.ConvertToOpenAPI31(bytes); err != nil {
+			return nil, fmt.Errorf("failed to convert to openapi %s: %w", consts.OpenAPIVersion31, err)
+		}
+	}
+	return bytes, nil
+}
+
+// comment returns the head comment of the generated YAML files, the banner preceded by the file header.
+func (g *OpenAPIGenerator) comment() (string, error) {
+	banner := "Generated with " + consts.PluginNameThriftRpcSwagger + "\n" + consts.InfoURL + consts.PluginNameThriftRpcSwagger
+	header, err := common.FileHeader(g.args.FileHeader)
+	if err != nil || header == "" {
+		return banner, err
+	}
+	return header + "\n\n" + banner, nil
+}
+
+// defaultDescription returns the description of the document unless the openapi.document annotation
+// sets one, which is the doc comment of the thrift file when DescriptionFromFile is set and it has one.
+func (g *OpenAPIGenerator) defaultDescription() string {
+	if g.args.DescriptionFromFile {
+		comment, err := common.FileDocComment(g.ast.Filename)
+		if err != nil {
+			logs.Warnf("Error reading the doc comment of %s: %s", g.ast.Filename, err)
+		} else if description := g.filterCommentString(comment); description != "" {
+			return description
+		}
+	}
+	return consts.DefaultInfoDesc
+}
+
+// services returns the services of the thrift file the document describes.
+func (g *OpenAPIGenerator) services() []*thrift_reflection.ServiceDescriptor {
+	if g.service == "" {
+		return g.fileDesc.GetServices()
+	}
+	for _, s := range g.fileDesc.GetServices() {
+		if s.GetName() == g.service {
+			return []*thrift_reflection.ServiceDescriptor{s}
+		}
+	}
+	return nil
+}
+
 func (g *OpenAPIGenerator) getDocumentOption(obj interface{}) error {
 	serviceOrStruct, name := g.getDocumentAnnotationInWhichServiceOrStruct()
 
@@ -230,13 +479,23 @@ func (g *OpenAPIGenerator) getDocumentOption(obj interface{}) error {
 	return nil
 }
 
-func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*thrift_reflection.ServiceDescriptor) {
+// addPathsToDocument adds the operations of the functions of the services to the document. It fails
+// on exceptions sharing a status code.
+func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*thrift_reflection.ServiceDescriptor) error {
 	var err error
 	for _, s := range services {
 		if s != nil {
+			// The external docs of the first service are used unless the openapi.document annotation sets them
+			if d.ExternalDocs == nil {
+				err = utils.ParseServiceOption(s, consts.OpenapiExternalDocs, &d.ExternalDocs)
+				if err != nil {
+					logs.Errorf("Error parsing service option: %s", err)
+				}
+			}
 			annotationsCount := 0
 			for _, m := range s.GetMethods() {
-				var inputDesc, outputDesc, throwDesc *thrift_reflection.StructDescriptor
+				var inputDesc, outputDesc *thrift_reflection.StructDescriptor
+				var throwDescs []*thrift_reflection.StructDescriptor
 
 				if len(m.Args) > 0 {
 					if len(m.Args) > 1 {
@@ -263,11 +522,13 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*t
 					logs.Errorf("now only support struct type for output, but got %s", m.Response.Name)
 				}
 
-				if len(m.ThrowExceptions) > 0 {
-					throwDesc, err = m.ThrowExceptions[0].GetType().GetExceptionDescriptor()
+				for _, e := range m.ThrowExceptions {
+					throwDesc, err := e.GetType().GetExceptionDescriptor()
 					if err != nil {
 						logs.Errorf("Error getting exception descriptor: %s", err)
+						continue
 					}
+					throwDescs = append(throwDescs, throwDesc)
 				}
 				var host string
 
@@ -278,30 +539,117 @@ func (g *OpenAPIGenerator) addPathsToDocument(d *openapi.Document, services []*t
 				}
 
 				annotationsCount++
-				operationID := s.GetName() + "_" + m.GetName()
-				path := "/" + m.GetName()
+				operationID := g.getOperationID(s.GetName(), m.GetName())
+				path := g.proxyPrefix + m.GetName()
 				comment := g.filterCommentString(m.Comments)
 
-				op, path2 := g.buildOperation(d, comment, operationID, s.GetName(), path, host, inputDesc, outputDesc, throwDesc)
+				op, path2, err := g.buildOperation(d, comment, operationID, getTagName(s), path, host, inputDesc, outputDesc, throwDescs)
+				if err != nil {
+					return err
+				}
+				op.Deprecated = g.isDeprecated(m.Annotations)
+				g.addContentTypes(op, m.Annotations)
+				g.addResponseHeaders(op, m.Annotations)
+				addStreamingMode(op, m)
 
 				newOp := &openapi.Operation{}
 				err = utils.ParseMethodOption(m, consts.OpenapiOperation, &newOp)
 				if err != nil {
 					logs.Errorf("Error parsing method option: %s", err)
 				}
+				// Tags from the annotation are added to the tag of the service instead of replacing it
+				op.Tags = appendTags(op.Tags, newOp.Tags)
+				newOp.Tags = nil
 				err = common.MergeStructs(op, newOp)
 				if err != nil {
 					logs.Errorf("Error merging method option: %s", err)
 				}
+				op.SpecificationExtension = addExtensions(op.SpecificationExtension, m.Annotations, "function '"+m.GetName()+"'")
+
+				var externalDocs *openapi.ExternalDocs
+				err = utils.ParseMethodOption(m, consts.OpenapiExternalDocs, &externalDocs)
+				if err != nil {
+					logs.Errorf("Error parsing method option: %s", err)
+				}
+				if externalDocs != nil {
+					op.ExternalDocs = externalDocs
+				}
 
 				g.addOperationToDocument(d, op, path2)
 			}
 			if annotationsCount > 0 {
 				comment := g.filterCommentString(s.Comments)
-				d.Tags = append(d.Tags, &openapi.Tag{Name: s.GetName(), Description: comment})
+				addTagToDocument(d, &openapi.Tag{Name: getTagName(s), Description: comment})
+			}
+		}
+	}
+	return nil
+}
+
+// getTagName returns the tag of the operations of the service, the service name unless
+// the openapi.tag annotation overrides it, so that several services can share a tag.
+func getTagName(s *thrift_reflection.ServiceDescriptor) string {
+	if tags, ok := s.Annotations[consts.OpenapiTag]; ok && len(tags) > 0 && tags[0] != "" {
+		return tags[0]
+	}
+	return s.GetName()
+}
+
+// addTagToDocument adds the tag of a service to the document, a tag with the same name declared
+// in the openapi.document annotation is kept and only takes the description if it has none.
+func addTagToDocument(d *openapi.Document, tag *openapi.Tag) {
+	for _, t := range d.Tags {
+		if t.Name == tag.Name {
+			if t.Description == "" {
+				t.Description = tag.Description
 			}
+			return
+		}
+	}
+	d.Tags = append(d.Tags, tag)
+}
+
+// appendTags appends the tags that aren't in the list yet, keeping their order.
+func appendTags(tags, more []string) []string {
+	for _, tag := range more {
+		tags = common.AppendUnique(tags, tag)
+	}
+	return tags
+}
+
+// addOperationTagsToDocument adds the tags the operations carry besides the tags of their services, e.g.
+// admin or beta, to the document, so that every tag referenced by an operation is listed.
+func addOperationTagsToDocument(d *openapi.Document) {
+	for _, path := range d.Paths.Path {
+		if path.Value.Post == nil {
+			continue
+		}
+		for _, tag := range path.Value.Post.Tags {
+			addTagToDocument(d, &openapi.Tag{Name: tag})
+		}
+	}
+}
+
+// sortTags sorts the tags declared in the openapi.document annotation first, in their declared
+// order, followed by the other tags in alphabetical order.
+func sortTags(tags []*openapi.Tag, declared []string) {
+	order := make(map[string]int, len(declared))
+	for i, name := range declared {
+		if _, ok := order[name]; !ok {
+			order[name] = i
 		}
 	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		oi, iok := order[tags[i].Name]
+		oj, jok := order[tags[j].Name]
+		if iok && jok {
+			return oi < oj
+		}
+		if iok || jok {
+			return iok
+		}
+		return tags[i].Name < tags[j].Name
+	})
 }
 
 func (g *OpenAPIGenerator) buildOperation(
@@ -313,8 +661,8 @@ func (g *OpenAPIGenerator) buildOperation(
 	host string,
 	inputDesc *thrift_reflection.StructDescriptor,
 	outputDesc *thrift_reflection.StructDescriptor,
-	throwDesc *thrift_reflection.StructDescriptor,
-) (*openapi.Operation, string) {
+	throwDescs []*thrift_reflection.StructDescriptor,
+) (*openapi.Operation, string, error) {
 	// Parameters array to hold all parameter objects
 	var parameters []*openapi.ParameterOrReference
 
@@ -342,11 +690,11 @@ func (g *OpenAPIGenerator) buildOperation(
 		var additionalProperties []*openapi.NamedMediaType
 		if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 			refSchema := &openapi.NamedSchemaOrReference{
-				Name:  inputDesc.GetName(),
+				Name:  g.getSchemaName(inputDesc),
 				Value: &openapi.SchemaOrReference{Schema: bodySchema},
 			}
 
-			ref := consts.ComponentSchemaPrefix + inputDesc.GetName()
+			ref := consts.ComponentSchemaPrefix + g.getSchemaName(inputDesc)
 
 			g.addSchemaToDocument(d, refSchema)
 
@@ -367,17 +715,16 @@ func (g *OpenAPIGenerator) buildOperation(
 					Content: &openapi.MediaTypes{
 						AdditionalProperties: additionalProperties,
 					},
+					Required: isRequestBodyRequired(inputDesc, bodySchema),
 				},
 			}
 		}
 	}
 
 	var (
-		desc                    string
-		contentOrEmpty          *openapi.MediaTypes
-		exceptionDesc           string
-		exceptionContentOrEmpty *openapi.MediaTypes
-		responses               *openapi.Responses
+		desc           string
+		contentOrEmpty *openapi.MediaTypes
+		responses      *openapi.Responses
 	)
 
 	if outputDesc != nil {
@@ -392,7 +739,7 @@ func (g *OpenAPIGenerator) buildOperation(
 			contentOrEmpty = content
 		}
 
-		if contentOrEmpty != nil || exceptionContentOrEmpty != nil {
+		if contentOrEmpty != nil {
 			responses = &openapi.Responses{
 				ResponseOrReference: []*openapi.NamedResponseOrReference{
 					{
@@ -409,15 +756,16 @@ func (g *OpenAPIGenerator) buildOperation(
 		}
 	}
 
-	if throwDesc != nil {
-		exceptionName, exceptionContent := g.getExceptionForStruct(d, throwDesc)
-		exceptionDesc = g.filterCommentString(throwDesc.Comments)
+	for i, throwDesc := range throwDescs {
+		statusCode := getExceptionCode(throwDesc, i)
+		exceptionDesc := g.filterCommentString(throwDesc.Comments)
 
 		if exceptionDesc == "" {
 			exceptionDesc = consts.DefaultExceptionDesc
 		}
 
-		if len(exceptionContent.AdditionalProperties) != 0 {
+		var exceptionContentOrEmpty *openapi.MediaTypes
+		if exceptionContent := g.getExceptionForStruct(d, throwDesc); len(exceptionContent.AdditionalProperties) != 0 {
 			exceptionContentOrEmpty = exceptionContent
 		}
 
@@ -426,19 +774,21 @@ func (g *OpenAPIGenerator) buildOperation(
 				ResponseOrReference: []*openapi.NamedResponseOrReference{},
 			}
 		}
+		if hasResponse(responses, statusCode) {
+			return nil, "", fmt.Errorf("exception %s of operation %s uses the status code %s of another response, set another one with %s on the exception",
+				throwDesc.GetName(), operationID, statusCode, consts.ApiResponseCode)
+		}
 
 		if contentOrEmpty != nil || exceptionContentOrEmpty != nil {
-			responses = &openapi.Responses{
-				ResponseOrReference: append(responses.ResponseOrReference, &openapi.NamedResponseOrReference{
-					Name: exceptionName,
-					Value: &openapi.ResponseOrReference{
-						Response: &openapi.Response{
-							Description: exceptionDesc,
-							Content:     exceptionContentOrEmpty,
-						},
+			responses.ResponseOrReference = append(responses.ResponseOrReference, &openapi.NamedResponseOrReference{
+				Name: statusCode,
+				Value: &openapi.ResponseOrReference{
+					Response: &openapi.Response{
+						Description: exceptionDesc,
+						Content:     exceptionContentOrEmpty,
 					},
-				}),
-			}
+				},
+			})
 		}
 	}
 
@@ -461,7 +811,7 @@ func (g *OpenAPIGenerator) buildOperation(
 		op.Servers = append(op.Servers, &openapi.Server{URL: host})
 	}
 
-	return op, path
+	return op, path, nil
 }
 
 func (g *OpenAPIGenerator) getDocumentAnnotationInWhichServiceOrStruct() (string, string) {
@@ -490,10 +840,10 @@ func (g *OpenAPIGenerator) getResponseForStruct(d *openapi.Document, desc *thrif
 
 	if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  desc.GetName(),
+			Name:  g.getSchemaName(desc),
 			Value: &openapi.SchemaOrReference{Schema: bodySchema},
 		}
-		ref := consts.ComponentSchemaPrefix + desc.GetName()
+		ref := consts.ComponentSchemaPrefix + g.getSchemaName(desc)
 		g.addSchemaToDocument(d, refSchema)
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 			Name: consts.ContentTypeJSON,
@@ -512,17 +862,17 @@ func (g *OpenAPIGenerator) getResponseForStruct(d *openapi.Document, desc *thrif
 	return consts.StatusOK, content
 }
 
-func (g *OpenAPIGenerator) getExceptionForStruct(d *openapi.Document, desc *thrift_reflection.StructDescriptor) (string, *openapi.MediaTypes) {
+func (g *OpenAPIGenerator) getExceptionForStruct(d *openapi.Document, desc *thrift_reflection.StructDescriptor) *openapi.MediaTypes {
 	bodySchema := g.getSchemaByOption(desc)
 
 	var additionalProperties []*openapi.NamedMediaType
 
 	if bodySchema != nil && bodySchema.Properties != nil && len(bodySchema.Properties.AdditionalProperties) > 0 {
 		refSchema := &openapi.NamedSchemaOrReference{
-			Name:  desc.GetName(),
+			Name:  g.getSchemaName(desc),
 			Value: &openapi.SchemaOrReference{Schema: bodySchema},
 		}
-		ref := consts.ComponentSchemaPrefix + desc.GetName()
+		ref := consts.ComponentSchemaPrefix + g.getSchemaName(desc)
 		g.addSchemaToDocument(d, refSchema)
 		additionalProperties = append(additionalProperties, &openapi.NamedMediaType{
 			Name: consts.ContentTypeJSON,
@@ -538,7 +888,30 @@ func (g *OpenAPIGenerator) getExceptionForStruct(d *openapi.Document, desc *thri
 		AdditionalProperties: additionalProperties,
 	}
 
-	return consts.StatusBadRequest, content
+	return content
+}
+
+// getExceptionCode returns the status code of the response of the i-th exception thrown by a
+// function, which can be set by the `api.response_code` annotation on the exception. The first
+// exception defaults to 400 and the following ones to 500.
+func getExceptionCode(desc *thrift_reflection.StructDescriptor, i int) string {
+	if codes := desc.Annotations[consts.ApiResponseCode]; len(codes) > 0 && codes[0] != "" {
+		return codes[0]
+	}
+	if i == 0 {
+		return consts.StatusBadRequest
+	}
+	return consts.StatusInternalServerError
+}
+
+// hasResponse reports whether the responses contain a response for the status code.
+func hasResponse(responses *openapi.Responses, statusCode string) bool {
+	for _, r := range responses.ResponseOrReference {
+		if r.Name == statusCode {
+			return true
+		}
+	}
+	return false
 }
 
 func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.StructDescriptor) *openapi.Schema {
@@ -573,8 +946,22 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 			continue
 		}
 
+		deprecated := g.isDeprecated(field.Annotations)
+		readOnly, writeOnly := accessMode(field)
+		// A $ref can't carry siblings, so wrap it with `allOf` to mark it deprecated, read-only or write-only or extend it
+		if (deprecated || readOnly || writeOnly || hasExtensions(field.Annotations)) && fieldSchema.IsSetReference() {
+			fieldSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{
+				AllOf: []*openapi.SchemaOrReference{fieldSchema},
+			}}
+		}
+
 		if fieldSchema.IsSetSchema() {
 			fieldSchema.Schema.Description = description
+			fieldSchema.Schema.Deprecated = deprecated
+			fieldSchema.Schema.ReadOnly = readOnly
+			fieldSchema.Schema.WriteOnly = writeOnly
+			applyFormatAnnotation(fieldSchema, field)
+			applyConstAnnotations(fieldSchema, field)
 			newFieldSchema := &openapi.Schema{}
 			err := utils.ParseFieldOption(field, consts.OpenapiProperty, &newFieldSchema)
 			if err != nil {
@@ -584,6 +971,7 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 			if err != nil {
 				logs.Errorf("Error merging field option: %s", err)
 			}
+			fieldSchema.Schema.SpecificationExtension = addExtensions(fieldSchema.Schema.SpecificationExtension, field.Annotations, "field '"+field.GetName()+"'")
 		}
 
 		definitionProperties.AdditionalProperties = append(
@@ -606,13 +994,249 @@ func (g *OpenAPIGenerator) getSchemaByOption(inputDesc *thrift_reflection.Struct
 			logs.Errorf("Error merging struct option: %s", err)
 		}
 	}
+	schema.SpecificationExtension = addExtensions(schema.SpecificationExtension, inputDesc.Annotations, "struct '"+inputDesc.GetName()+"'")
+	g.closeSchema(schema)
 
 	schema.Required = required
 	return schema
 }
 
+// addContentTypes documents the media types listed by the openapi.content_types annotation
+// of a function next to JSON, sharing the schemas of the JSON request and responses.
+func (g *OpenAPIGenerator) addContentTypes(op *openapi.Operation, annotations map[string][]string) {
+	var contentTypes []string
+	for _, v := range annotations[consts.OpenapiContentTypes] {
+		for _, contentType := range strings.Split(v, ",") {
+			contentType = strings.TrimSpace(contentType)
+			if contentType != "" && contentType != consts.ContentTypeJSON {
+				contentTypes = common.AppendUnique(contentTypes, contentType)
+			}
+		}
+	}
+	if len(contentTypes) == 0 {
+		return
+	}
+
+	addToContent := func(content *openapi.MediaTypes) {
+		if content == nil {
+			return
+		}
+		for _, mediaType := range content.AdditionalProperties {
+			if mediaType.Name != consts.ContentTypeJSON {
+				continue
+			}
+			for _, contentType := range contentTypes {
+				content.AdditionalProperties = append(content.AdditionalProperties, &openapi.NamedMediaType{
+					Name:  contentType,
+					Value: &openapi.MediaType{Schema: mediaType.Value.Schema},
+				})
+			}
+			return
+		}
+	}
+
+	if op.RequestBody != nil && op.RequestBody.RequestBody != nil {
+		addToContent(op.RequestBody.RequestBody.Content)
+	}
+	if op.Responses != nil {
+		for _, response := range op.Responses.ResponseOrReference {
+			if response.Value != nil && response.Value.Response != nil {
+				addToContent(response.Value.Response.Content)
+			}
+		}
+	}
+}
+
+// addStreamingMode marks the operation of a Kitex streaming method, annotated with streaming.mode,
+// with the x-streaming extension set to client, server or bidi, so that it is not taken for a unary call.
+func addStreamingMode(op *openapi.Operation, m *thrift_reflection.MethodDescriptor) {
+	modes, ok := m.Annotations[consts.KitexStreamingMode]
+	if !ok || len(modes) == 0 {
+		return
+	}
+	var mode string
+	switch modes[0] {
+	case consts.StreamingModeUnary, "":
+		return
+	case consts.StreamingModeClient, consts.StreamingModeServer:
+		mode = modes[0]
+	case consts.StreamingModeBidirectional:
+		mode = consts.ExtensionStreamingBidi
+	default:
+		logs.Warnf("unknown streaming mode '%s' of function '%s', it is documented as a unary call", modes[0], m.GetName())
+		return
+	}
+	logs.Warnf("function '%s' is a %s streaming method, it is marked with %s and can't be called through the swagger proxy", m.GetName(), modes[0], consts.ExtensionStreaming)
+	op.SpecificationExtension = append(op.SpecificationExtension, &openapi.NamedAny{
+		Name:  consts.ExtensionStreaming,
+		Value: &openapi.Any{Yaml: mode},
+	})
+}
+
+// addResponseHeaders documents the backward metadata keys listed in the openapi.response_headers
+// annotation as headers of the successful response, the proxy returns them as response headers.
+func (g *OpenAPIGenerator) addResponseHeaders(op *openapi.Operation, annotations map[string][]string) {
+	keys := responseHeaderKeys(annotations[consts.OpenapiResponseHeaders])
+	if len(keys) == 0 {
+		return
+	}
+
+	if op.Responses == nil {
+		op.Responses = &openapi.Responses{}
+	}
+	var response *openapi.Response
+	for _, r := range op.Responses.ResponseOrReference {
+		if r.Name == consts.StatusOK && r.Value != nil && r.Value.Response != nil {
+			response = r.Value.Response
+		}
+	}
+	if response == nil {
+		response = &openapi.Response{Description: consts.DefaultResponseDesc}
+		op.Responses.ResponseOrReference = append(op.Responses.ResponseOrReference, &openapi.NamedResponseOrReference{
+			Name:  consts.StatusOK,
+			Value: &openapi.ResponseOrReference{Response: response},
+		})
+	}
+	if response.Headers == nil {
+		response.Headers = &openapi.HeadersOrReferences{}
+	}
+	for _, key := range keys {
+		response.Headers.AdditionalProperties = append(response.Headers.AdditionalProperties, &openapi.NamedHeaderOrReference{
+			Name: key,
+			Value: &openapi.HeaderOrReference{
+				Header: &openapi.Header{
+					Description: "Backward metadata returned by the service",
+					Schema: &openapi.SchemaOrReference{
+						Schema: &openapi.Schema{Type: "string"},
+					},
+				},
+			},
+		})
+	}
+}
+
+// responseHeaderKeys returns the backward metadata keys of the comma separated values of the
+// openapi.response_headers annotation.
+func responseHeaderKeys(values []string) []string {
+	var keys []string
+	for _, v := range values {
+		for _, key := range strings.Split(v, ",") {
+			key = strings.TrimSpace(key)
+			if key != "" {
+				keys = common.AppendUnique(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// enumDescriptions returns the x-enumDescriptions extension listing the comments of the values of the enum
+// in the order of its enum, so that Swagger UI and the other consumers show the meaning of each value. An
+// enum whose values have no comments gets none.
+func (g *OpenAPIGenerator) enumDescriptions(enumDesc *thrift_reflection.EnumDescriptor) []*openapi.NamedAny {
+	descriptions := make([]string, 0, len(enumDesc.GetValues()))
+	commented := false
+	for _, v := range enumDesc.GetValues() {
+		description := g.filterCommentString(v.Comments)
+		commented = commented || description != ""
+		descriptions = append(descriptions, strconv.Quote(description))
+	}
+	if !commented {
+		return nil
+	}
+	return []*openapi.NamedAny{{
+		Name:  consts.ExtensionEnumDescriptions,
+		Value: &openapi.Any{Yaml: "[" + strings.Join(descriptions, ", ") + "]"},
+	}}
+}
+
+// isDeprecated reports whether the annotations mark a function or field as deprecated.
+func (g *OpenAPIGenerator) isDeprecated(annotations map[string][]string) bool {
+	if g.args.DeprecatedAnnotation != "" {
+		return utils.IsAnnotationEnabled(annotations, g.args.DeprecatedAnnotation)
+	}
+	return utils.IsAnnotationEnabled(annotations, consts.ApiDeprecated, consts.Deprecated)
+}
+
+// accessMode reports whether the openapi.read_only or openapi.write_only annotation of a field marks it as
+// returned by the server only, e.g. an ID assigned by the server, or as sent by the client only, e.g. a password.
+// A field marked with both is marked with neither.
+func accessMode(field *thrift_reflection.FieldDescriptor) (readOnly, writeOnly bool) {
+	readOnly = utils.IsAnnotationEnabled(field.Annotations, consts.OpenapiReadOnly)
+	writeOnly = utils.IsAnnotationEnabled(field.Annotations, consts.OpenapiWriteOnly)
+	if readOnly && writeOnly {
+		logs.Warnf("openapi.read_only and openapi.write_only of field '%s' are ignored, a field can't be both", field.GetName())
+		return false, false
+	}
+	return readOnly, writeOnly
+}
+
+// applyFormatAnnotation sets the format of a string field from its api.format annotation, e.g. uuid,
+// email or uri, which clients use to validate the values. The annotation is ignored with a warning on
+// the fields of the other types and on the strings that already have a format, such as binary.
+func applyFormatAnnotation(fieldSchema *openapi.SchemaOrReference, field *thrift_reflection.FieldDescriptor) {
+	values := field.Annotations[consts.ApiFormat]
+	if len(values) == 0 || values[0] == "" || fieldSchema == nil || !fieldSchema.IsSetSchema() {
+		return
+	}
+	if fieldSchema.Schema.Type != "string" || fieldSchema.Schema.Format != "" {
+		logs.Warnf("api.format of field '%s' is ignored, only a string field without a format takes one", field.GetName())
+		return
+	}
+	fieldSchema.Schema.Format = values[0]
+}
+
+// applyConstAnnotations sets the enum of a field to the elements of the list or set const named by its
+// openapi.enum_const annotation, and its example to the value of the const named by its openapi.example_const
+// annotation, e.g. ROLES, or base.ROLES for a const of an included file, so that the values declared in the
+// IDL aren't repeated in the annotations. The enum of a list or set field applies to its elements.
+func applyConstAnnotations(fieldSchema *openapi.SchemaOrReference, field *thrift_reflection.FieldDescriptor) {
+	if fieldSchema == nil || !fieldSchema.IsSetSchema() {
+		return
+	}
+	if names := field.Annotations[consts.OpenapiEnumConst]; len(names) > 0 && names[0] != "" {
+		values, err := thriftutils.ConstListYAML(field, names[0])
+		if err != nil {
+			logs.Warnf("openapi.enum_const of field '%s' is ignored: %s", field.GetName(), err)
+		} else {
+			enum := make([]*openapi.Any, 0, len(values))
+			for _, value := range values {
+				enum = append(enum, &openapi.Any{Yaml: value})
+			}
+			schema := fieldSchema.Schema
+			if schema.Items != nil && len(schema.Items.SchemaOrReference) == 1 && schema.Items.SchemaOrReference[0].IsSetSchema() {
+				schema = schema.Items.SchemaOrReference[0].Schema
+			}
+			schema.Enum = enum
+			schema.SpecificationExtension = removeExtension(schema.SpecificationExtension, consts.ExtensionEnumDescriptions)
+		}
+	}
+	if names := field.Annotations[consts.OpenapiExampleConst]; len(names) > 0 && names[0] != "" {
+		value, err := thriftutils.ConstYAML(field, names[0])
+		if err != nil {
+			logs.Warnf("openapi.example_const of field '%s' is ignored: %s", field.GetName(), err)
+		} else {
+			fieldSchema.Schema.Example = &openapi.Any{Yaml: value}
+		}
+	}
+}
+
+// getOperationID returns the operation ID of the method of the service formatted by the operation ID template.
+func (g *OpenAPIGenerator) getOperationID(service, method string) string {
+	operationID, err := common.FormatOperationID(g.operationIDTemplate, service, method)
+	if err != nil {
+		logs.Errorf("Error formatting operation id of '%s': %s", method, err)
+		return service + "_" + method
+	}
+	return operationID
+}
+
 // filterCommentString removes linter rules from comments.
+// With PreserveMarkdown only the comment markers are removed, so that the markdown keeps its layout.
 func (g *OpenAPIGenerator) filterCommentString(str string) string {
+	if g.args.PreserveMarkdown {
+		return common.MarkdownComment(str)
+	}
 	var comments []string
 	matches := regexp.MustCompile(consts.CommentPatternRegexp).FindAllStringSubmatch(str, -1)
 
@@ -653,8 +1277,109 @@ func (g *OpenAPIGenerator) filterCommentString(str string) string {
 	return strings.Join(comments, "\n")
 }
 
+// isRequestBodyRequired reports whether a field of the request is declared `required`
+// or listed in the `required` of the body schema.
+func isRequestBodyRequired(desc *thrift_reflection.StructDescriptor, bodySchema *openapi.Schema) bool {
+	for _, field := range desc.GetFields() {
+		if field.IsRequired() {
+			return true
+		}
+	}
+	return bodySchema != nil && len(bodySchema.Required) > 0
+}
+
+// indexOf returns the index of the element in the slice, or -1 if it's absent.
+func indexOf(s []string, e string) int {
+	for i, a := range s {
+		if a == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// nestedStructDescriptor returns the struct held by a field of the type, unwrapping the elements
+// of lists and sets and the values of maps.
+func nestedStructDescriptor(fieldType *thrift_reflection.TypeDescriptor) *thrift_reflection.StructDescriptor {
+	for fieldType.IsMap() || fieldType.IsList() {
+		fieldType = fieldType.GetValueType()
+	}
+	if fieldType.IsException() {
+		exceptionDesc, _ := fieldType.GetExceptionDescriptor()
+		return exceptionDesc
+	}
+	if !fieldType.IsStruct() {
+		return nil
+	}
+	structDesc, _ := fieldType.GetStructDescriptor()
+	return structDesc
+}
+
+// removeExtension removes the named extension, e.g. one that no longer applies to the schema.
+func removeExtension(extensions []*openapi.NamedAny, name string) []*openapi.NamedAny {
+	kept := extensions[:0]
+	for _, named := range extensions {
+		if named.Name != name {
+			kept = append(kept, named)
+		}
+	}
+	return kept
+}
+
+// hasExtensions reports whether the annotations set specification extensions with openapi.extension.
+func hasExtensions(annotations map[string][]string) bool {
+	return len(annotations[consts.OpenapiExtension]) > 0
+}
+
+// addExtensions sets the specification extensions of the openapi.extension annotations on the extensions
+// of an object, replacing the extensions of the same name, so that they are passed verbatim to the document.
+func addExtensions(extensions []*openapi.NamedAny, annotations map[string][]string, owner string) []*openapi.NamedAny {
+	parsed, err := common.ParseExtensions(annotations[consts.OpenapiExtension])
+	if err != nil {
+		logs.Errorf("Error parsing extension option of %s: %s", owner, err)
+		return extensions
+	}
+	for _, extension := range parsed {
+		value := &openapi.Any{Yaml: extension.Yaml}
+		replaced := false
+		for _, named := range extensions {
+			if named.Name == extension.Name {
+				named.Value, replaced = value, true
+			}
+		}
+		if !replaced {
+			extensions = append(extensions, &openapi.NamedAny{Name: extension.Name, Value: value})
+		}
+	}
+	return extensions
+}
+
+// closeSchema disallows the properties not declared by the object schema when the ClosedSchemas
+// argument is set, unless the openapi.schema annotation already sets additionalProperties.
+func (g *OpenAPIGenerator) closeSchema(schema *openapi.Schema) {
+	if g.args.ClosedSchemas && schema.AdditionalProperties == nil {
+		schema.AdditionalProperties = &openapi.AdditionalPropertiesItem{Boolean: false}
+	}
+}
+
 func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, structs []*thrift_reflection.StructDescriptor) {
 	for _, s := range structs {
+		structKey := s.GetFilepath() + "#" + s.GetName()
+		// The struct is already being walked by an outer call, which adds its schema
+		if depth := indexOf(g.walkingStructs, structKey); depth >= 0 {
+			if depth < g.skippedDepth {
+				g.skippedDepth = depth
+			}
+			continue
+		}
+		// Structs shared by many others are walked once instead of once per path to them
+		required := len(g.requiredTypeDesc)
+		if walked, ok := g.walkedStructs[structKey]; ok && walked == required {
+			continue
+		}
+		depth := len(g.walkingStructs)
+		outerSkippedDepth := g.skippedDepth
+		g.skippedDepth = math.MaxInt32
 		var sls []*thrift_reflection.StructDescriptor
 		for _, f := range s.GetFields() {
 			fieldType := f.GetType()
@@ -662,20 +1387,29 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				logs.Errorf("Warning: field type is nil for field: %s\n", f.GetName())
 				continue
 			}
-			if fieldType.IsStruct() {
-				structDesc, _ := fieldType.GetStructDescriptor()
+			if structDesc := nestedStructDescriptor(fieldType); structDesc != nil {
 				sls = append(sls, structDesc)
 			}
 		}
 		if len(sls) > 0 {
+			g.walkingStructs = append(g.walkingStructs, structKey)
 			g.addSchemasForStructsToDocument(d, sls)
+			g.walkingStructs = g.walkingStructs[:len(g.walkingStructs)-1]
+		}
+		// The walk depends on the outer structs when it skipped one of them, so it can't be reused elsewhere
+		reusable := g.skippedDepth >= depth
+		if outerSkippedDepth < g.skippedDepth {
+			g.skippedDepth = outerSkippedDepth
 		}
 
-		schemaName := s.GetName()
+		schemaName := g.getSchemaName(s)
 
 		// Only generate this if we need it and haven't already generated it.
 		if !common.Contains(g.requiredSchemas, schemaName) ||
 			common.Contains(g.generatedSchemas, schemaName) {
+			if reusable && len(g.requiredTypeDesc) == required {
+				g.walkedStructs[structKey] = required
+			}
 			continue
 		}
 
@@ -695,8 +1429,22 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				continue
 			}
 
+			deprecated := g.isDeprecated(field.Annotations)
+			readOnly, writeOnly := accessMode(field)
+			// A $ref can't carry siblings, so wrap it with `allOf` to mark it deprecated, read-only or write-only or extend it
+			if (deprecated || readOnly || writeOnly || hasExtensions(field.Annotations)) && fieldSchema.IsSetReference() {
+				fieldSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{
+					AllOf: []*openapi.SchemaOrReference{fieldSchema},
+				}}
+			}
+
 			if fieldSchema.IsSetSchema() {
 				fieldSchema.Schema.Description = description
+				fieldSchema.Schema.Deprecated = deprecated
+				fieldSchema.Schema.ReadOnly = readOnly
+				fieldSchema.Schema.WriteOnly = writeOnly
+				applyFormatAnnotation(fieldSchema, field)
+				applyConstAnnotations(fieldSchema, field)
 				newFieldSchema := &openapi.Schema{}
 				err := utils.ParseFieldOption(field, consts.OpenapiProperty, &newFieldSchema)
 				if err != nil {
@@ -706,6 +1454,7 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				if err != nil {
 					logs.Errorf("Error merging field option: %s", err)
 				}
+				fieldSchema.Schema.SpecificationExtension = addExtensions(fieldSchema.Schema.SpecificationExtension, field.Annotations, "field '"+field.GetName()+"'")
 			}
 
 			fName := field.GetName()
@@ -736,6 +1485,8 @@ func (g *OpenAPIGenerator) addSchemasForStructsToDocument(d *openapi.Document, s
 				logs.Errorf("Error merging struct option: %s", err)
 			}
 		}
+		schema.SpecificationExtension = addExtensions(schema.SpecificationExtension, s.Annotations, "struct '"+s.GetName()+"'")
+		g.closeSchema(schema)
 
 		// Add the schema to the components.schema list.
 		g.addSchemaToDocument(d, &openapi.NamedSchemaOrReference{
@@ -773,8 +1524,103 @@ func (g *OpenAPIGenerator) addOperationToDocument(d *openapi.Document, op *opena
 	selectedPathItem.Value.Post = op
 }
 
+// getSchemaName returns the component schema name of the struct, see newSchemaNames.
+func (g *OpenAPIGenerator) getSchemaName(desc *thrift_reflection.StructDescriptor) string {
+	if name, ok := g.schemaNames[desc.GetFilepath()+"#"+desc.GetName()]; ok {
+		return name
+	}
+	// The openapi.schema_name annotation renames the schema and the references to it, e.g. to present
+	// an internal struct under a public name
+	if names := desc.Annotations[consts.OpenapiSchemaName]; len(names) > 0 && names[0] != "" {
+		return names[0]
+	}
+	return desc.GetName()
+}
+
+// newSchemaNames names the component schemas of the structs, unions and exceptions of the thrift file
+// and of the files it includes, keyed by their file path and name. The names set by the openapi.schema_name
+// annotation are claimed first, it fails if one is set twice or is the name of another struct of the thrift
+// file. The other structs are named after themselves, unless FQSchemaNaming is enabled or the name is
+// already taken by a struct of another file, then they are prefixed with the namespace of their file. The
+// structs of the main file take their names first, then those of the included files in the order of their
+// paths, so that the names don't depend on the order the structs are walked in. A prefixed name that is
+// still taken, e.g. by a struct of another file with the same namespace, is numbered.
+func newSchemaNames(ast *parser.Thrift, fileDesc *thrift_reflection.FileDescriptor, fqSchemaNaming bool) (map[string]string, error) {
+	var includes []string
+	for t := range ast.DepthFirstSearch() {
+		if t.Filename != ast.Filename {
+			includes = append(includes, t.Filename)
+		}
+	}
+	sort.Strings(includes)
+
+	type fileStruct struct {
+		path string
+		fd   *thrift_reflection.FileDescriptor
+		s    *thrift_reflection.StructDescriptor
+	}
+	var structs []fileStruct
+	gd := thrift_reflection.GetGlobalDescriptor(fileDesc)
+	for _, path := range append([]string{ast.Filename}, includes...) {
+		fd := gd.LookupFD(path)
+		if fd == nil {
+			continue
+		}
+		for _, ss := range [][]*thrift_reflection.StructDescriptor{fd.GetStructs(), fd.GetUnions(), fd.GetExceptions()} {
+			for _, s := range ss {
+				structs = append(structs, fileStruct{path: path, fd: fd, s: s})
+			}
+		}
+	}
+
+	names := make(map[string]string)
+	// taken holds the struct each name is given to
+	taken := make(map[string]string)
+	for _, fs := range structs {
+		if values := fs.s.Annotations[consts.OpenapiSchemaName]; len(values) > 0 && values[0] != "" {
+			if other, ok := taken[values[0]]; ok {
+				return nil, fmt.Errorf("%s %s of struct %s is already set on struct %s", consts.OpenapiSchemaName, values[0], fs.s.GetName(), other)
+			}
+			taken[values[0]] = fs.s.GetName()
+			names[fs.path+"#"+fs.s.GetName()] = values[0]
+		}
+	}
+	for _, fs := range structs {
+		key := fs.path + "#" + fs.s.GetName()
+		if _, ok := names[key]; ok {
+			continue
+		}
+		name := fs.s.GetName()
+		if other, ok := taken[name]; ok && fs.path == ast.Filename {
+			return nil, fmt.Errorf("%s %s of struct %s is the name of another struct, set another one", consts.OpenapiSchemaName, name, other)
+		}
+		if fqSchemaNaming || taken[name] != "" {
+			name = namespacedSchemaName(fs.fd, fs.s.GetName())
+		}
+		for i := 2; taken[name] != ""; i++ {
+			name = fmt.Sprintf("%s%d", namespacedSchemaName(fs.fd, fs.s.GetName()), i)
+		}
+		taken[name] = fs.s.GetName()
+		names[key] = name
+	}
+	return names, nil
+}
+
+// namespacedSchemaName prefixes the name with the go namespace of the file, or with the file name if
+// the file has no namespace.
+func namespacedSchemaName(fd *thrift_reflection.FileDescriptor, name string) string {
+	namespace := fd.Namespaces["go"]
+	if namespace == "" {
+		namespace = fd.Namespaces["*"]
+	}
+	if namespace == "" {
+		namespace = strings.TrimSuffix(filepath.Base(fd.Filepath), filepath.Ext(fd.Filepath))
+	}
+	return strings.ReplaceAll(namespace, "/", ".") + "." + name
+}
+
 func (g *OpenAPIGenerator) schemaReferenceForMessage(message *thrift_reflection.StructDescriptor) string {
-	schemaName := message.GetName()
+	schemaName := g.getSchemaName(message)
 	if !common.Contains(g.requiredSchemas, schemaName) {
 		g.requiredSchemas = append(g.requiredSchemas, schemaName)
 		g.requiredTypeDesc = append(g.requiredTypeDesc, message)
@@ -825,6 +1671,10 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 			return nil
 		}
 		kindSchema = g.schemaOrReferenceForField(typedefDesc.Type)
+		// Keep the alias name, a $ref can't carry a title
+		if kindSchema != nil && kindSchema.Schema != nil {
+			kindSchema.Schema.Title = typedefDesc.GetAlias()
+		}
 
 	case fieldType.IsEnum():
 		enumDesc, err := fieldType.GetEnumDescriptor()
@@ -833,12 +1683,21 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 			return nil
 		}
 		kindSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{}}
-		kindSchema.Schema.Type = "string"
-		kindSchema.Schema.Format = "enum"
 		kindSchema.Schema.Enum = make([]*openapi.Any, 0, len(enumDesc.GetValues()))
-		for _, v := range enumDesc.GetValues() {
-			kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: v.GetName()})
+		if g.args.EnumType == consts.EnumTypeInteger {
+			kindSchema.Schema.Type = "integer"
+			kindSchema.Schema.Format = "int32"
+			for _, v := range enumDesc.GetValues() {
+				kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: strconv.FormatInt(v.GetValue(), 10)})
+			}
+		} else {
+			kindSchema.Schema.Type = "string"
+			kindSchema.Schema.Format = "enum"
+			for _, v := range enumDesc.GetValues() {
+				kindSchema.Schema.Enum = append(kindSchema.Schema.Enum, &openapi.Any{Yaml: v.GetName()})
+			}
 		}
+		kindSchema.Schema.SpecificationExtension = g.enumDescriptions(enumDesc)
 
 	case fieldType.IsUnion():
 		unionDesc, err := fieldType.GetUnionDescriptor()
@@ -854,7 +1713,15 @@ func (g *OpenAPIGenerator) schemaOrReferenceForField(fieldType *thrift_reflectio
 		}
 
 	case fieldType.IsException():
-		logs.Errorf("Error: exception type not supported: %s for field", fieldType.GetName())
+		exceptionDesc, err := fieldType.GetExceptionDescriptor()
+		if err != nil {
+			logs.Errorf("Error getting exception descriptor: %s", err)
+			return nil
+		}
+		ref := g.schemaReferenceForMessage(exceptionDesc)
+		kindSchema = &openapi.SchemaOrReference{
+			Reference: &openapi.Reference{Xref: ref},
+		}
 
 	default:
 		kindSchema = &openapi.SchemaOrReference{Schema: &openapi.Schema{}}
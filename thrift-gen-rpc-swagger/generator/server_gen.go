@@ -26,6 +26,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
 	"github.com/hertz-contrib/swagger-generate/common/consts"
@@ -35,9 +36,21 @@ import (
 )
 
 type ServerGenerator struct {
-	IdlPath   string
-	KitexAddr string
-	OutputDir string
+	IdlPath       string
+	KitexAddr     string
+	ProxyPrefix   string
+	RPCTimeout    int64
+	MaxRetryTimes int
+	Registry      string
+	RegistryAddr  string
+	ServiceName   string
+	HertzAddr     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	OutputDir     string
+	SplitSchemas  bool
+	// ResponseHeaders are the backward metadata keys the proxy returns as response headers, by method
+	ResponseHeaders map[string][]string
 }
 
 func NewServerGenerator(ast *parser.Thrift, args *args.Arguments) (*ServerGenerator, error) {
@@ -63,10 +76,58 @@ func NewServerGenerator(ast *parser.Thrift, args *args.Arguments) (*ServerGenera
 		return nil, err
 	}
 
+	rpcTimeout, err := utils.RPCTimeoutMillis(args.RPCTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateRetryTimes(args.MaxRetryTimes); err != nil {
+		return nil, err
+	}
+
+	var registryAddr, serviceName string
+	if args.Registry != "" {
+		registryAddr, err = utils.RegistryAddr(args.Registry, args.RegistryAddr)
+		if err != nil {
+			return nil, err
+		}
+		// Kitex calls the last service of the IDL by default
+		serviceName = args.ServiceName
+		if serviceName == "" && len(ast.Services) > 0 {
+			serviceName = ast.Services[len(ast.Services)-1].Name
+		}
+		if serviceName == "" {
+			return nil, errors.New("ServiceName must be set to resolve the service with a registry")
+		}
+	}
+
+	hertzAddr := args.HertzAddr
+	if (args.TLSCertFile == "") != (args.TLSKeyFile == "") {
+		return nil, errors.New("TLSCertFile and TLSKeyFile must be set together")
+	}
+	if args.TLSCertFile != "" {
+		if hertzAddr == "" {
+			hertzAddr = consts.DefaultHertzAddr
+		}
+		if err := validateAddress(hertzAddr); err != nil {
+			return nil, err
+		}
+	}
+
 	return &ServerGenerator{
-		IdlPath:   idlPath,
-		KitexAddr: kitexAddr,
-		OutputDir: outputDir,
+		IdlPath:         idlPath,
+		KitexAddr:       kitexAddr,
+		ProxyPrefix:     utils.ProxyPrefix(args.ProxyPrefix),
+		RPCTimeout:      rpcTimeout,
+		MaxRetryTimes:   args.MaxRetryTimes,
+		Registry:        args.Registry,
+		RegistryAddr:    registryAddr,
+		ServiceName:     serviceName,
+		HertzAddr:       hertzAddr,
+		TLSCertFile:     args.TLSCertFile,
+		TLSKeyFile:      args.TLSKeyFile,
+		OutputDir:       outputDir,
+		SplitSchemas:    args.OutputMode == consts.OutputModeSplit,
+		ResponseHeaders: getResponseHeaders(ast),
 	}, nil
 }
 
@@ -74,7 +135,7 @@ func (g *ServerGenerator) Generate() ([]*plugin.Generated, error) {
 	filePath := filepath.Join(g.OutputDir, consts.DefaultOutputSwaggerFile)
 
 	if utils.FileExists(filePath) {
-		updatedContent, err := updateVariables(filePath, g.KitexAddr, g.IdlPath)
+		updatedContent, err := g.updateVariables(filePath)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +162,7 @@ func (g *ServerGenerator) Generate() ([]*plugin.Generated, error) {
 	}}, nil
 }
 
-func updateVariables(filePath, newKitexAddr, newIdlPath string) (string, error) {
+func (g *ServerGenerator) updateVariables(filePath string) (string, error) {
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return "", err
@@ -110,12 +171,61 @@ func updateVariables(filePath, newKitexAddr, newIdlPath string) (string, error)
 	kitexAddrPattern := regexp.MustCompile(`kitexAddr\s*=\s*"(.*?)"`)
 	idlPathPattern := regexp.MustCompile(`idlFile\s*=\s*"(.*?)"`)
 
-	updatedContent := kitexAddrPattern.ReplaceAllString(string(content), fmt.Sprintf(`kitexAddr = "%s"`, newKitexAddr))
-	updatedContent = idlPathPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`idlFile = "%s"`, newIdlPath))
+	updatedContent := kitexAddrPattern.ReplaceAllString(string(content), fmt.Sprintf(`kitexAddr = "%s"`, g.KitexAddr))
+	updatedContent = idlPathPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`idlFile = "%s"`, g.IdlPath))
+
+	var ok bool
+	if updatedContent, ok = utils.UpdateProxyPrefix(updatedContent, g.ProxyPrefix); !ok {
+		return "", fmt.Errorf("%s does not route the methods with the proxy prefix, remove it to generate it again", filePath)
+	}
+
+	// A swagger.go generated before the client policy calls without timeout and retries
+	rpcTimeoutPattern := regexp.MustCompile(`rpcTimeout\s*=\s*\d+ \* time\.Millisecond`)
+	maxRetryTimesPattern := regexp.MustCompile(`maxRetryTimes\s*=\s*\d+`)
+	if rpcTimeoutPattern.MatchString(updatedContent) && maxRetryTimesPattern.MatchString(updatedContent) {
+		updatedContent = rpcTimeoutPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`rpcTimeout    = %d * time.Millisecond`, g.RPCTimeout))
+		updatedContent = maxRetryTimesPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`maxRetryTimes = %d`, g.MaxRetryTimes))
+	} else {
+		logs.Warnf("%s calls without timeout and retries, remove it to generate it again", filePath)
+	}
+
+	// The response headers are only returned by a newly generated swagger.go
+	if len(g.ResponseHeaders) > 0 && !strings.Contains(updatedContent, "responseHeaders") {
+		logs.Warnf("%s does not return the response headers of openapi.response_headers, remove it to generate it again", filePath)
+	}
+
+	// The registry is only wired into a newly generated swagger.go
+	var registry string
+	if m := regexp.MustCompile(`"github\.com/kitex-contrib/registry-(\w+)"`).FindStringSubmatch(updatedContent); m != nil {
+		registry = m[1]
+	}
+	if registry != g.Registry {
+		logs.Warnf("%s does not resolve the service as configured, remove it to generate it again", filePath)
+	} else if registry != "" {
+		registryAddrPattern := regexp.MustCompile(`registryAddr\s*=\s*"(.*?)"`)
+		serviceNamePattern := regexp.MustCompile(`serviceName\s*=\s*"(.*?)"`)
+		updatedContent = registryAddrPattern.ReplaceAllString(updatedContent, fmt.Sprintf(`registryAddr = "%s"`, g.RegistryAddr))
+		updatedContent = serviceNamePattern.ReplaceAllString(updatedContent, fmt.Sprintf(`serviceName  = "%s"`, g.ServiceName))
+	}
 
 	return updatedContent, nil
 }
 
+// getResponseHeaders returns the backward metadata keys listed by the openapi.response_headers
+// annotations of the functions, by function name. Only these keys are returned as response headers,
+// the other backward metadata is only merged into the response body.
+func getResponseHeaders(ast *parser.Thrift) map[string][]string {
+	headers := make(map[string][]string)
+	for _, s := range ast.Services {
+		for _, f := range s.Functions {
+			if keys := responseHeaderKeys(f.Annotations.Get(consts.OpenapiResponseHeaders)); len(keys) > 0 {
+				headers[f.Name] = keys
+			}
+		}
+	}
+	return headers
+}
+
 func validateAddress(addr string) error {
 	if !strings.Contains(addr, ":") {
 		return errors.New("address must include a port (e.g., '127.0.0.1:8888')")